@@ -1,11 +1,16 @@
 package telebot
 
-import "io"
+import (
+	"context"
+	"io"
+)
 
 // API is the interface that wraps all basic methods for interacting
 // with Telegram Bot API.
 type API interface {
 	Raw(method string, payload interface{}) ([]byte, error)
+	RawContext(ctx context.Context, method string, payload interface{}) ([]byte, error)
+	WithContext(ctx context.Context) API
 
 	Accept(query *PreCheckoutQuery, errorMessage ...string) error
 	AddStickerToSet(of Recipient, name string, sticker InputSticker) error
@@ -43,6 +48,7 @@ type API interface {
 	Download(file *File, localFilename string) error
 	Edit(msg Editable, what interface{}, opts ...interface{}) (*Message, error)
 	EditCaption(msg Editable, caption string, opts ...interface{}) (*Message, error)
+	EditChecklist(businessConnectionID string, msg Editable, c InputChecklist, markup ...*ReplyMarkup) (*Message, error)
 	EditGeneralTopic(chat *Chat, topic *Topic) error
 	EditInviteLink(chat Recipient, link *ChatInviteLink) (*ChatInviteLink, error)
 	EditMedia(msg Editable, media Inputtable, opts ...interface{}) (*Message, error)
@@ -53,6 +59,7 @@ type API interface {
 	Forward(to Recipient, msg Editable, opts ...interface{}) (*Message, error)
 	ForwardMany(to Recipient, msgs []Editable, opts ...*SendOptions) ([]Message, error)
 	GameScores(user Recipient, msg Editable) ([]GameHighScore, error)
+	Gifts() ([]Gift, error)
 	HideGeneralTopic(chat *Chat) error
 	InviteLink(chat *Chat) (string, error)
 	Leave(chat Recipient) error
@@ -68,6 +75,8 @@ type API interface {
 	Promote(chat *Chat, member *ChatMember) error
 	React(to Recipient, msg Editable, r Reactions) error
 	RefundStars(to Recipient, chargeID string) error
+	RemoveChatVerification(chat Recipient) error
+	RemoveUserVerification(user *User) error
 	RemoveWebhook(dropPending ...bool) error
 	ReopenGeneralTopic(chat *Chat) error
 	ReopenTopic(chat *Chat, topic *Topic) error
@@ -76,8 +85,10 @@ type API interface {
 	Respond(c *Callback, resp ...*CallbackResponse) error
 	Restrict(chat *Chat, member *ChatMember) error
 	RevokeInviteLink(chat Recipient, link string) (*ChatInviteLink, error)
+	SavePreparedInlineMessage(user *User, r Result, opts PreparedInlineOptions) (*PreparedInlineMessage, error)
 	Send(to Recipient, what interface{}, opts ...interface{}) (*Message, error)
 	SendAlbum(to Recipient, a Album, opts ...interface{}) ([]Message, error)
+	SendGift(to Recipient, giftID string, opts ...GiftOptions) error
 	SendPaid(to Recipient, stars int, a PaidAlbum, opts ...interface{}) (*Message, error)
 	SetAdminTitle(chat *Chat, user *User, title string) error
 	SetCommands(opts ...interface{}) error
@@ -98,6 +109,7 @@ type API interface {
 	SetStickerPosition(sticker string, position int) error
 	SetStickerSetThumb(of Recipient, set *StickerSet) error
 	SetStickerSetTitle(s StickerSet) error
+	SetUserEmojiStatus(user *User, emojiID string, expirationUnixtime int64) error
 	SetWebhook(w *Webhook) error
 	Ship(query *ShippingQuery, what ...interface{}) error
 	StarTransactions(offset, limit int) ([]StarTransaction, error)
@@ -114,5 +126,7 @@ type API interface {
 	UnpinAllTopicMessages(chat *Chat, topic *Topic) error
 	UploadSticker(to Recipient, format StickerSetFormat, f File) (*File, error)
 	UserBoosts(chat, user Recipient) ([]Boost, error)
+	VerifyChat(chat Recipient, description ...string) error
+	VerifyUser(user *User, description ...string) error
 	Webhook() (*Webhook, error)
 }
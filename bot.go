@@ -1,6 +1,7 @@
 package telebot
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -44,12 +45,25 @@ func NewBot(pref Settings) (*Bot, error) {
 
 		Updates:  make(chan Update, pref.Updates),
 		handlers: make(map[string]HandlerFunc),
+		matchers: &[]matcher{},
 		stop:     make(chan chan struct{}),
 
 		synchronous: pref.Synchronous,
 		verbose:     pref.Verbose,
 		parseMode:   pref.ParseMode,
 		client:      client,
+		stopClient:  &clientStopper{},
+		inflight:    &sync.WaitGroup{},
+	}
+
+	if pref.SignCallbacks {
+		bot.signer = newCallbackSigner(pref.Token, pref.CallbackSecret, pref.CallbackTTL)
+	}
+	if pref.RateLimit != nil {
+		bot.limiter = newRateLimiter(*pref.RateLimit)
+	}
+	if pref.Workers > 0 && !pref.Synchronous {
+		bot.pool = newWorkerPool(pref.Workers, pref.WorkerQueue, pref.ShardByChat)
 	}
 
 	if pref.Offline {
@@ -77,14 +91,56 @@ type Bot struct {
 
 	group       *Group
 	handlers    map[string]HandlerFunc
+	matchers    *[]matcher
 	synchronous bool
 	verbose     bool
 	parseMode   ParseMode
 	stop        chan chan struct{}
 	client      *http.Client
 
-	stopMu     sync.RWMutex
-	stopClient chan struct{}
+	limiter *rateLimiter
+	pool    *workerPool
+	signer  *callbackSigner
+
+	// reply is only set for the copies made by Context, whose first
+	// call is put into the webhook response.
+	reply *webhookReply
+
+	// stopClient and inflight are shared with the copies made by WithContext.
+	stopClient *clientStopper
+	inflight   *sync.WaitGroup
+
+	// ctx is only set for the copies made by WithContext.
+	ctx context.Context
+}
+
+// clientStopper cancels in-flight requests when the bot is about to stop.
+type clientStopper struct {
+	mu sync.RWMutex
+	ch chan struct{}
+
+	// poll cancels only the polling requests, so the handlers
+	// can finish their work during the graceful shutdown.
+	poll chan struct{}
+
+	// drained is closed once the handlers are done
+	// and the webhook server can be shut down.
+	drained chan struct{}
+
+	// shutdown bounds the webhook server shutdown, if it's
+	// made by Shutdown, and servers tracks its completion.
+	shutdown context.Context
+	servers  sync.WaitGroup
+}
+
+// shutdownContext returns the context of the webhook server shutdown.
+func (s *clientStopper) shutdownContext() context.Context {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.shutdown != nil {
+		return s.shutdown
+	}
+	return context.Background()
 }
 
 // Settings represents a utility struct for passing certain
@@ -103,6 +159,20 @@ type Settings struct {
 	// It makes ProcessUpdate return after the handler is finished.
 	Synchronous bool
 
+	// Workers limits the number of handlers running in parallel by
+	// a fixed pool of goroutines, instead of starting a goroutine per
+	// update. Ignored if Synchronous is set.
+	Workers int
+
+	// WorkerQueue is the capacity of the workers queue, defaulted to Workers.
+	// Once it's full, processing of updates blocks until a worker is free,
+	// which slows the poller down.
+	WorkerQueue int
+
+	// ShardByChat makes the workers handle updates of the same chat in
+	// order, while updates of different chats still run in parallel.
+	ShardByChat bool
+
 	// Verbose forces bot to log all upcoming requests.
 	// Use for debugging purposes only.
 	Verbose bool
@@ -122,6 +192,24 @@ type Settings struct {
 
 	// Offline allows to create a bot without network for testing purposes.
 	Offline bool
+
+	// RateLimit enables the outbound scheduler, which respects Telegram
+	// limits on sending messages and retries requests on FloodError.
+	// See RateLimit for the defaults.
+	RateLimit *RateLimit
+
+	// SignCallbacks signs the data of the inline buttons with unique,
+	// so the callbacks forged by a client or replayed from another chat
+	// are rejected with ErrCallbackForged before the handler runs.
+	SignCallbacks bool
+
+	// CallbackSecret is a key of the signature.
+	// Defaults to the key derived from the token.
+	CallbackSecret []byte
+
+	// CallbackTTL, if positive, also rejects the callbacks
+	// of the buttons signed earlier than CallbackTTL ago.
+	CallbackTTL time.Duration
 }
 
 var defaultOnError = func(err error, c Context) {
@@ -142,6 +230,44 @@ func (b *Bot) debug(err error) {
 	}
 }
 
+// WithContext returns the bot bound to the given context. It's the
+// context-aware variant of every API method: once the context is done,
+// the in-flight requests made through the returned value are aborted.
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+//	defer cancel()
+//
+//	msg, err := b.WithContext(ctx).Send(to, "Hello!")
+func (b *Bot) WithContext(ctx context.Context) API {
+	if ctx == nil {
+		panic("telebot: nil context")
+	}
+	return boundBot{b.bind(ctx)}
+}
+
+// boundBot hides the context-bound copy of the bot behind
+// the API interface, so it can't be started or stopped.
+type boundBot struct {
+	*Bot
+}
+
+// bind returns a shallow copy of the bot, whose API calls use the
+// given context. All the state is shared with the original bot.
+func (b *Bot) bind(ctx context.Context) *Bot {
+	cp := *b
+	cp.ctx = ctx
+	return &cp
+}
+
+// context returns the context the bot is bound to,
+// or the background one for the original bot.
+func (b *Bot) context() context.Context {
+	if b.ctx != nil {
+		return b.ctx
+	}
+	return context.Background()
+}
+
 // Group returns a new group.
 func (b *Bot) Group() *Group {
 	return &Group{b: b}
@@ -171,22 +297,39 @@ var (
 //		return c.Respond(&tele.CallbackResponse{Text: "Hello!"})
 //	})
 //
+// The message text and callback data may also be matched by a regexp,
+// and any update by a Predicate. Such endpoints are only evaluated,
+// in the order of registration, when no exact endpoint matches
+// the update, and before the On* events. The submatches are
+// accessed with Context.Matches:
+//
+//	b.Handle(regexp.MustCompile(`^order #(\d+)$`), func(c tele.Context) error {
+//		return showOrder(c, c.Matches()[1])
+//	})
+//
 // Middleware usage:
 //
 //	b.Handle("/ban", onBan, middleware.Whitelist(ids...))
 func (b *Bot) Handle(endpoint interface{}, h HandlerFunc, m ...MiddlewareFunc) {
-	end := extractEndpoint(endpoint)
-	if end == "" {
-		panic("telebot: unsupported endpoint")
-	}
-
 	if len(b.group.middleware) > 0 {
 		m = appendMiddleware(b.group.middleware, m)
 	}
 
-	b.handlers[end] = func(c Context) error {
+	handler := func(c Context) error {
 		return applyMiddleware(h, m...)(c)
 	}
+
+	if mt, ok := newMatcher(endpoint, handler); ok {
+		*b.matchers = append(*b.matchers, mt)
+		return
+	}
+
+	end := extractEndpoint(endpoint)
+	if end == "" {
+		panic("telebot: unsupported endpoint")
+	}
+
+	b.handlers[end] = handler
 }
 
 // Trigger executes the registered handler by the endpoint.
@@ -212,14 +355,17 @@ func (b *Bot) Start() {
 	}
 
 	// do nothing if called twice
-	b.stopMu.Lock()
-	if b.stopClient != nil {
-		b.stopMu.Unlock()
+	b.stopClient.mu.Lock()
+	if b.stopClient.ch != nil {
+		b.stopClient.mu.Unlock()
 		return
 	}
 
-	b.stopClient = make(chan struct{})
-	b.stopMu.Unlock()
+	b.stopClient.ch = make(chan struct{})
+	b.stopClient.poll = make(chan struct{})
+	b.stopClient.drained = make(chan struct{})
+	b.stopClient.shutdown = nil
+	b.stopClient.mu.Unlock()
 
 	stop := make(chan struct{})
 	stopConfirm := make(chan struct{})
@@ -245,17 +391,96 @@ func (b *Bot) Start() {
 }
 
 // Stop gracefully shuts the poller down.
+// It doesn't wait for the running handlers, see Shutdown.
 func (b *Bot) Stop() {
-	b.stopMu.Lock()
-	if b.stopClient != nil {
-		close(b.stopClient)
-		b.stopClient = nil
+	var drained chan struct{}
+
+	b.stopClient.mu.Lock()
+	if b.stopClient.ch != nil {
+		drained = b.stopClient.drained
+		close(b.stopClient.ch)
+		close(b.stopClient.poll)
+		b.stopClient.ch = nil
+	}
+	b.stopClient.mu.Unlock()
+
+	confirm := make(chan struct{})
+	b.stop <- confirm
+	<-confirm
+
+	if drained != nil {
+		close(drained)
 	}
-	b.stopMu.Unlock()
+}
+
+// Shutdown gracefully stops the bot without losing the work in progress.
+// It stops the poller first, so no new updates are received, then processes
+// the updates left in the Updates channel and waits for the running handlers
+// to finish. Finally, the webhook server, if such used, is shut down, and
+// Shutdown waits for it to finish the requests in progress.
+//
+// If the context is done before the handlers have finished, their in-flight
+// requests are cancelled and the context error is returned.
+func (b *Bot) Shutdown(ctx context.Context) error {
+	b.stopClient.mu.Lock()
+	if b.stopClient.ch == nil {
+		b.stopClient.mu.Unlock()
+		return nil
+	}
+	stopCh, drained := b.stopClient.ch, b.stopClient.drained
+	close(b.stopClient.poll)
+	b.stopClient.ch = nil
+	b.stopClient.shutdown = ctx
+	b.stopClient.mu.Unlock()
+
+	// Cancel whatever is left in-flight in any case.
+	defer close(stopCh)
 
 	confirm := make(chan struct{})
 	b.stop <- confirm
 	<-confirm
+
+	// Let the webhook server go, even if the handlers aren't done.
+	err := b.drain(ctx)
+	close(drained)
+	if err != nil {
+		return err
+	}
+
+	return waitContext(ctx, &b.stopClient.servers)
+}
+
+// drain processes the updates left in the Updates channel
+// and waits for the running handlers to finish.
+func (b *Bot) drain(ctx context.Context) error {
+	for {
+		select {
+		case upd := <-b.Updates:
+			b.ProcessUpdate(upd)
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		break
+	}
+	return waitContext(ctx, b.inflight)
+}
+
+// waitContext waits for the group until the context is done.
+func waitContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // NewMarkup simply returns newly created markup instance.
@@ -292,8 +517,14 @@ func (b *Bot) Send(to Recipient, what interface{}, opts ...interface{}) (*Messag
 
 	switch object := what.(type) {
 	case string:
+		if sendOpts.Split {
+			return b.sendTextSplit(to, object, sendOpts)
+		}
 		return b.sendText(to, object, sendOpts)
 	case Sendable:
+		if caption := captionOf(object); sendOpts.Split && caption != nil {
+			return b.sendCaptionSplit(to, object, caption, sendOpts)
+		}
 		return object.Send(b, to, sendOpts)
 	default:
 		return nil, ErrUnsupportedWhat
@@ -337,7 +568,9 @@ func (b *Bot) SendPaid(to Recipient, stars int, a PaidAlbum, opts ...interface{}
 	}
 
 	params["media"] = "[" + strings.Join(media, ",") + "]"
-	b.embedSendOptions(params, sendOpts)
+	if err := b.embedSendOptions(params, sendOpts); err != nil {
+		return nil, err
+	}
 
 	data, err := b.sendFiles("sendPaidMedia", files, params)
 	if err != nil {
@@ -382,7 +615,9 @@ func (b *Bot) SendAlbum(to Recipient, a Album, opts ...interface{}) ([]Message,
 		"chat_id": to.Recipient(),
 		"media":   "[" + strings.Join(media, ",") + "]",
 	}
-	b.embedSendOptions(params, sendOpts)
+	if err := b.embedSendOptions(params, sendOpts); err != nil {
+		return nil, err
+	}
 
 	data, err := b.sendFiles("sendMediaGroup", files, params)
 	if err != nil {
@@ -445,7 +680,9 @@ func (b *Bot) Forward(to Recipient, msg Editable, opts ...interface{}) (*Message
 	}
 
 	sendOpts := b.extractOptions(opts)
-	b.embedSendOptions(params, sendOpts)
+	if err := b.embedSendOptions(params, sendOpts); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("forwardMessage", params)
 	if err != nil {
@@ -482,7 +719,9 @@ func (b *Bot) Copy(to Recipient, msg Editable, opts ...interface{}) (*Message, e
 	}
 
 	sendOpts := b.extractOptions(opts)
-	b.embedSendOptions(params, sendOpts)
+	if err := b.embedSendOptions(params, sendOpts); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("copyMessage", params)
 	if err != nil {
@@ -566,7 +805,9 @@ func (b *Bot) Edit(msg Editable, what interface{}, opts ...interface{}) (*Messag
 	}
 
 	sendOpts := b.extractOptions(opts)
-	b.embedSendOptions(params, sendOpts)
+	if err := b.embedSendOptions(params, sendOpts); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw(method, params)
 	if err != nil {
@@ -596,9 +837,15 @@ func (b *Bot) EditReplyMarkup(msg Editable, markup *ReplyMarkup) (*Message, erro
 	if markup == nil {
 		// will delete reply markup
 		markup = &ReplyMarkup{}
+	} else {
+		// the caller's markup may be reused
+		markup = markup.copy()
 	}
 
 	processButtons(markup.InlineKeyboard)
+	if err := b.signButtons(params["chat_id"], markup.InlineKeyboard); err != nil {
+		return nil, err
+	}
 	data, _ := json.Marshal(markup)
 	params["reply_markup"] = string(data)
 
@@ -630,7 +877,9 @@ func (b *Bot) EditCaption(msg Editable, caption string, opts ...interface{}) (*M
 	}
 
 	sendOpts := b.extractOptions(opts)
-	b.embedSendOptions(params, sendOpts)
+	if err := b.embedSendOptions(params, sendOpts); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("editMessageCaption", params)
 	if err != nil {
@@ -694,7 +943,9 @@ func (b *Bot) EditMedia(msg Editable, media Inputtable, opts ...interface{}) (*M
 	params := make(map[string]string)
 
 	sendOpts := b.extractOptions(opts)
-	b.embedSendOptions(params, sendOpts)
+	if err := b.embedSendOptions(params, sendOpts); err != nil {
+		return nil, err
+	}
 
 	im := media.InputMedia()
 	im.Media = repr
@@ -960,22 +1211,38 @@ func (b *Bot) File(file *File) (io.ReadCloser, error) {
 	url := b.URL + "/file/bot" + b.Token + "/" + f.FilePath
 	file.FilePath = f.FilePath // saving file path
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	ctx, cancel := b.withStop(b.context())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
+		cancel()
 		return nil, wrapError(err)
 	}
 
 	resp, err := b.client.Do(req)
 	if err != nil {
+		cancel()
 		return nil, wrapError(err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()
+		cancel()
 		return nil, fmt.Errorf("telebot: expected status 200 but got %s", resp.Status)
 	}
 
-	return resp.Body, nil
+	return &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// cancelReadCloser releases the request context once the body is closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelReadCloser) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
 }
 
 // StopLiveLocation stops broadcasting live message location
@@ -995,7 +1262,9 @@ func (b *Bot) StopLiveLocation(msg Editable, opts ...interface{}) (*Message, err
 	}
 
 	sendOpts := b.extractOptions(opts)
-	b.embedSendOptions(params, sendOpts)
+	if err := b.embedSendOptions(params, sendOpts); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("stopMessageLiveLocation", params)
 	if err != nil {
@@ -1019,7 +1288,9 @@ func (b *Bot) StopPoll(msg Editable, opts ...interface{}) (*Poll, error) {
 	}
 
 	sendOpts := b.extractOptions(opts)
-	b.embedSendOptions(params, sendOpts)
+	if err := b.embedSendOptions(params, sendOpts); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("stopPoll", params)
 	if err != nil {
@@ -1058,7 +1329,9 @@ func (b *Bot) Pin(msg Editable, opts ...interface{}) error {
 	}
 
 	sendOpts := b.extractOptions(opts)
-	b.embedSendOptions(params, sendOpts)
+	if err := b.embedSendOptions(params, sendOpts); err != nil {
+		return err
+	}
 
 	_, err := b.Raw("pinChatMessage", params)
 	return err
@@ -20,6 +20,21 @@ import (
 // It also handles API errors, so you only need to unwrap
 // result field from json data.
 func (b *Bot) Raw(method string, payload interface{}) ([]byte, error) {
+	return b.RawContext(b.context(), method, payload)
+}
+
+// RawContext is the same as Raw, but the request is bound to the given
+// context. Once the context is done, the in-flight request is aborted.
+func (b *Bot) RawContext(ctx context.Context, method string, payload interface{}) ([]byte, error) {
+	if b.reply != nil && b.reply.claim(method, payload) {
+		return webhookReplyResult, nil
+	}
+	return b.schedule(ctx, method, payload, true, func() ([]byte, error) {
+		return b.raw(ctx, method, payload)
+	})
+}
+
+func (b *Bot) raw(ctx context.Context, method string, payload interface{}) ([]byte, error) {
 	url := b.URL + "/bot" + b.Token + "/" + method
 
 	var buf bytes.Buffer
@@ -27,24 +42,9 @@ func (b *Bot) Raw(method string, payload interface{}) ([]byte, error) {
 		return nil, err
 	}
 
-	// Cancel the request immediately without waiting for the timeout
-	// when bot is about to stop.
-	// This may become important if doing long polling with long timeout.
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := b.withStop(ctx)
 	defer cancel()
 
-	go func() {
-		b.stopMu.RLock()
-		stopCh := b.stopClient
-		b.stopMu.RUnlock()
-
-		select {
-		case <-stopCh:
-			cancel()
-		case <-ctx.Done():
-		}
-	}()
-
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
 	if err != nil {
 		return nil, wrapError(err)
@@ -92,6 +92,22 @@ func (b *Bot) sendFiles(method string, files map[string]File, params map[string]
 		return b.Raw(method, params)
 	}
 
+	// Readers can't be uploaded twice, so only the files
+	// on disk are safe to be retried on FloodError.
+	retry := true
+	for _, file := range rawFiles {
+		if _, ok := file.(string); !ok {
+			retry = false
+		}
+	}
+
+	ctx := b.context()
+	return b.schedule(ctx, method, params, retry, func() ([]byte, error) {
+		return b.sendMultipart(ctx, method, files, rawFiles, params)
+	})
+}
+
+func (b *Bot) sendMultipart(ctx context.Context, method string, files map[string]File, rawFiles map[string]interface{}, params map[string]string) ([]byte, error) {
 	pipeReader, pipeWriter := io.Pipe()
 	writer := multipart.NewWriter(pipeWriter)
 
@@ -118,7 +134,18 @@ func (b *Bot) sendFiles(method string, files map[string]File, params map[string]
 
 	url := b.URL + "/bot" + b.Token + "/" + method
 
-	resp, err := b.client.Post(url, writer.FormDataContentType(), pipeReader)
+	ctx, cancel := b.withStop(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, pipeReader)
+	if err != nil {
+		err = wrapError(err)
+		pipeReader.CloseWithError(err)
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := b.client.Do(req)
 	if err != nil {
 		err = wrapError(err)
 		pipeReader.CloseWithError(err)
@@ -139,6 +166,47 @@ func (b *Bot) sendFiles(method string, files map[string]File, params map[string]
 	return data, extractOk(data)
 }
 
+// withStop derives a context from the given one, which is also cancelled
+// immediately, without waiting for the timeout, when bot is about to stop.
+// This may become important if doing long polling with long timeout.
+func (b *Bot) withStop(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	b.stopClient.mu.RLock()
+	stopCh := b.stopClient.ch
+	b.stopClient.mu.RUnlock()
+
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// withPollStop is the same as withStop, but the context is also cancelled
+// when the bot stops polling for the graceful shutdown.
+func (b *Bot) withPollStop(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := b.withStop(ctx)
+
+	b.stopClient.mu.RLock()
+	pollCh := b.stopClient.poll
+	b.stopClient.mu.RUnlock()
+
+	go func() {
+		select {
+		case <-pollCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
 func addFileToWriter(writer *multipart.Writer, filename, field string, file interface{}) error {
 	var reader io.Reader
 	if r, ok := file.(io.Reader); ok {
@@ -181,7 +249,9 @@ func (b *Bot) sendText(to Recipient, text string, opt *SendOptions) (*Message, e
 		"chat_id": to.Recipient(),
 		"text":    text,
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("sendMessage", params)
 	if err != nil {
@@ -240,7 +310,10 @@ func (b *Bot) getUpdates(offset, limit int, timeout time.Duration, allowed []str
 		params["limit"] = strconv.Itoa(limit)
 	}
 
-	data, err := b.Raw("getUpdates", params)
+	ctx, cancel := b.withPollStop(b.context())
+	defer cancel()
+
+	data, err := b.RawContext(ctx, "getUpdates", params)
 	if err != nil {
 		return nil, err
 	}
@@ -262,7 +335,9 @@ func (b *Bot) forwardCopyMany(to Recipient, msgs []Editable, key string, opts ..
 	embedMessages(params, msgs)
 
 	if len(opts) > 0 {
-		b.embedSendOptions(params, opts[0])
+		if err := b.embedSendOptions(params, opts[0]); err != nil {
+			return nil, err
+		}
 	}
 
 	data, err := b.Raw(key, params)
@@ -1,13 +1,16 @@
 package telebot
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -43,6 +46,11 @@ func testRawServer(w http.ResponseWriter, r *http.Request) {
 
 		w.WriteHeader(400)
 		w.Write(data)
+
+	// hangs until the client gives up
+	case strings.HasSuffix(r.URL.Path, "/testSlow"):
+		io.Copy(ioutil.Discard, r.Body)
+		<-r.Context().Done()
 	}
 }
 
@@ -75,6 +83,40 @@ func TestRaw(t *testing.T) {
 	assert.EqualError(t, err, "telegram: unknown error (400)")
 }
 
+func TestRawContext(t *testing.T) {
+	b, err := NewBot(Settings{Offline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(testRawServer))
+	defer srv.Close()
+
+	b.URL = srv.URL
+	b.client = srv.Client()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = b.RawContext(ctx, "testSlow", nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	_, err = b.WithContext(ctx).Raw("testSlow", nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// reachable from the handlers through the interface
+	var api API = b
+	_, err = api.WithContext(ctx).RawContext(ctx, "testSlow", nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	c := NewContext(b, Update{Message: &Message{Chat: &Chat{ID: 1}}})
+	assert.Equal(t, context.Background(), c.StdContext())
+
+	c.SetStdContext(ctx)
+	assert.Equal(t, ctx, c.StdContext())
+	assert.Error(t, c.Send("hello"))
+}
+
 func TestExtractOk(t *testing.T) {
 	data := []byte(`{"ok": true, "result": {}}`)
 	require.NoError(t, extractOk(data))
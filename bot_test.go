@@ -1,13 +1,17 @@
 package telebot
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -319,6 +323,42 @@ func TestBotProcessUpdate(t *testing.T) {
 		assert.Equal(t, "webapp", c.Message().WebAppData.Data)
 		return nil
 	})
+	b.Handle(OnReaction, func(c Context) error {
+		assert.NotNil(t, c.Reaction())
+		assert.Equal(t, int64(1), c.Chat().ID)
+		if c.Reaction().ActorChat != nil {
+			assert.Nil(t, c.Sender())
+		} else {
+			assert.Equal(t, int64(2), c.Sender().ID)
+		}
+		return nil
+	})
+	b.Handle(OnGift, func(c Context) error {
+		assert.Equal(t, "gift", c.Message().Gift.Gift.ID)
+		return nil
+	})
+	b.Handle(OnUniqueGift, func(c Context) error {
+		assert.Equal(t, "unique", c.Message().UniqueGift.Gift.Name)
+		return nil
+	})
+	b.Handle(OnChecklist, func(c Context) error {
+		assert.Equal(t, "checklist", c.Message().Checklist.Title)
+		return nil
+	})
+	b.Handle(OnChecklistTasksDone, func(c Context) error {
+		assert.Equal(t, []int{1}, c.Message().ChecklistTasksDone.MarkedAsDone)
+		return nil
+	})
+	b.Handle(OnChecklistTasksAdded, func(c Context) error {
+		assert.Len(t, c.Message().ChecklistTasksAdded.Tasks, 1)
+		return nil
+	})
+	b.Handle(OnReactionCount, func(c Context) error {
+		assert.NotNil(t, c.ReactionCount())
+		assert.Equal(t, int64(1), c.Chat().ID)
+		assert.Nil(t, c.Sender())
+		return nil
+	})
 
 	b.ProcessUpdate(Update{Message: &Message{Text: "/start"}})
 	b.ProcessUpdate(Update{Message: &Message{Text: "/start@other_bot"}})
@@ -362,6 +402,54 @@ func TestBotProcessUpdate(t *testing.T) {
 	b.ProcessUpdate(Update{Poll: &Poll{ID: "poll"}})
 	b.ProcessUpdate(Update{PollAnswer: &PollAnswer{PollID: "poll"}})
 	b.ProcessUpdate(Update{Message: &Message{WebAppData: &WebAppData{Data: "webapp"}}})
+	b.ProcessUpdate(Update{MessageReaction: &MessageReaction{Chat: &Chat{ID: 1}, User: &User{ID: 2}}})
+	b.ProcessUpdate(Update{MessageReaction: &MessageReaction{Chat: &Chat{ID: 1}, ActorChat: &Chat{ID: 3}}})
+	b.ProcessUpdate(Update{MessageReactionCount: &MessageReactionCount{Chat: &Chat{ID: 1}}})
+	b.ProcessUpdate(Update{Message: &Message{Gift: &GiftInfo{Gift: Gift{ID: "gift"}}}})
+	b.ProcessUpdate(Update{Message: &Message{UniqueGift: &UniqueGiftInfo{Gift: UniqueGift{Name: "unique"}}}})
+	b.ProcessUpdate(Update{Message: &Message{Checklist: &Checklist{Title: "checklist"}}})
+	b.ProcessUpdate(Update{Message: &Message{ChecklistTasksDone: &ChecklistTasksDone{MarkedAsDone: []int{1}}}})
+	b.ProcessUpdate(Update{Message: &Message{ChecklistTasksAdded: &ChecklistTasksAdded{Tasks: []ChecklistTask{{ID: 1}}}}})
+}
+
+func TestBotContinue(t *testing.T) {
+	var errs []error
+	b, err := NewBot(Settings{
+		Synchronous: true,
+		Offline:     true,
+		OnError: func(err error, c Context) {
+			errs = append(errs, err)
+		},
+	})
+	require.NoError(t, err)
+
+	var trace []string
+	handler := func(name string, err error) HandlerFunc {
+		return func(c Context) error {
+			trace = append(trace, name+":"+c.Data())
+			return err
+		}
+	}
+
+	b.Handle("/start", handler("start", Continue))
+	b.Handle(Predicate(func(c Context) bool { return c.Text() != "" }), handler("analytics", Continue))
+	b.Handle(OnText, handler("text", nil))
+	b.Handle(OnPhoto, handler("photo", fmt.Errorf("wrapped: %w", Continue)))
+	b.Handle(OnMedia, handler("media", errors.New("failed")))
+	b.Handle(&Btn{Unique: "buy"}, handler("buy", Continue))
+	b.Handle(OnCallback, handler("callback", nil))
+
+	b.ProcessUpdate(Update{Message: &Message{Text: "/start ref", Chat: &Chat{ID: 1}}})
+	b.ProcessUpdate(Update{Message: &Message{Photo: &Photo{}, Chat: &Chat{ID: 1}}})
+	b.ProcessUpdate(Update{Callback: &Callback{Data: "\fbuy|1"}})
+
+	assert.Equal(t, []string{
+		"start:ref", "analytics:ref", "text:ref",
+		"photo:", "media:",
+		"buy:1", "callback:\fbuy|1",
+	}, trace)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "failed")
 }
 
 func TestBotOnError(t *testing.T) {
@@ -786,3 +874,79 @@ func TestBot(t *testing.T) {
 func sleep() {
 	time.Sleep(time.Second)
 }
+
+func TestBotShutdown(t *testing.T) {
+	b, err := NewBot(Settings{Offline: true})
+	require.NoError(t, err)
+
+	tp := newTestPoller()
+	b.Poller = tp
+
+	var (
+		started  = make(chan struct{})
+		finished int32
+	)
+
+	b.Handle(OnText, func(c Context) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+		return nil
+	})
+
+	go b.Start()
+	tp.updates <- Update{Message: &Message{Text: "text"}}
+	<-started
+
+	require.NoError(t, b.Shutdown(context.Background()))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&finished))
+
+	t.Run("deadline", func(t *testing.T) {
+		b, err := NewBot(Settings{Offline: true})
+		require.NoError(t, err)
+		b.Poller = newTestPoller()
+
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+
+		b.Handle(OnText, func(c Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+
+		go b.Start()
+		b.Updates <- Update{Message: &Message{Text: "text"}}
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		assert.Equal(t, context.DeadlineExceeded, b.Shutdown(ctx))
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := l.Addr().String()
+		require.NoError(t, l.Close())
+
+		b, err := NewBot(Settings{Offline: true})
+		require.NoError(t, err)
+		b.Poller = &Webhook{Listen: addr, IgnoreSetWebhook: true}
+
+		go b.Start()
+		require.Eventually(t, func() bool {
+			conn, err := net.Dial("tcp", addr)
+			if err == nil {
+				conn.Close()
+			}
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, b.Shutdown(context.Background()))
+
+		_, err = net.Dial("tcp", addr)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,317 @@
+package telebot
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// Callback data is limited by Telegram to 64 bytes.
+const callbackDataLimit = 64
+
+// Callback codec errors.
+var (
+	ErrCallbackDataTooLong = errors.New("telebot: callback data is too long")
+	ErrCallbackDataExpired = errors.New("telebot: callback data is not found in the store")
+	ErrBadCallbackData     = errors.New("telebot: bad callback data")
+)
+
+// CallbackStore keeps the callback payloads, which don't fit into
+// the button, by their short keys.
+type CallbackStore interface {
+	Load(key string) (data string, ok bool, err error)
+	Save(key, data string) error
+}
+
+// CallbackCodec packs the structs into the compact callback data.
+// The fields are encoded in the order of their declaration, so adding
+// new fields to the end keeps the old buttons decodable. Fields tagged
+// with `callback:"-"` are skipped. Supported field kinds are bools,
+// numbers, strings and nested structs.
+//
+//	type Buy struct {
+//		ItemID int64
+//		Count  int
+//	}
+//
+//	var codec tele.CallbackCodec
+//
+//	btn, err := codec.Btn("Buy", "buy", Buy{ItemID: 42, Count: 1})
+//
+//	b.Handle(&btn, func(c tele.Context) error {
+//		buy := tele.CallbackValue(c).(*Buy)
+//		...
+//	}, codec.Middleware(Buy{}))
+type CallbackCodec struct {
+	// Store, if set, keeps the payloads exceeding the 64 bytes limit.
+	// The button carries only a short hash of the payload then.
+	Store CallbackStore
+
+	// Signed reserves the room for the signature in the button,
+	// set it if the bot has Settings.SignCallbacks enabled.
+	Signed bool
+}
+
+// Marshal encodes v into the payload of the button with the unique.
+func (cc *CallbackCodec) Marshal(unique string, v interface{}) (string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return "", fmt.Errorf("telebot: callback data must be a struct, got %T", v)
+	}
+
+	buf, err := packStruct(nil, rv)
+	if err != nil {
+		return "", err
+	}
+
+	limit := callbackDataLimit
+	if cc.Signed {
+		limit -= callbackSigOverhead
+	}
+
+	data := base64.RawURLEncoding.EncodeToString(buf)
+	if len("\f"+unique+"|"+data) <= limit {
+		return data, nil
+	}
+	if cc.Store == nil {
+		return "", ErrCallbackDataTooLong
+	}
+
+	sum := sha256.Sum256([]byte(unique + "|" + data))
+	key := "#" + base64.RawURLEncoding.EncodeToString(sum[:12])
+	if err := cc.Store.Save(key, data); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Unmarshal decodes the payload into v, which must be a pointer to
+// the struct. If v implements the Validate() error method, it's called.
+func (cc *CallbackCodec) Unmarshal(data string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("telebot: callback data must be a pointer to struct, got %T", v)
+	}
+
+	if len(data) > 0 && data[0] == '#' {
+		if cc.Store == nil {
+			return ErrCallbackDataExpired
+		}
+		stored, ok, err := cc.Store.Load(data)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrCallbackDataExpired
+		}
+		data = stored
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return ErrBadCallbackData
+	}
+
+	rest, err := unpackStruct(buf, rv.Elem())
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return ErrBadCallbackData
+	}
+
+	if validator, ok := v.(interface{ Validate() error }); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+// Btn returns the inline button carrying the encoded v.
+func (cc *CallbackCodec) Btn(text, unique string, v interface{}) (Btn, error) {
+	data, err := cc.Marshal(unique, v)
+	if err != nil {
+		return Btn{}, err
+	}
+	return Btn{Text: text, Unique: unique, Data: data}, nil
+}
+
+const callbackValueKey = "callback_value"
+
+// Middleware returns a middleware, which decodes the callback data into
+// a new value of the prototype's type. The value is a pointer to the struct
+// and accessed with CallbackValue. Callbacks with the bad data are rejected
+// before the handler runs, returning the decoding error.
+func (cc *CallbackCodec) Middleware(prototype interface{}) MiddlewareFunc {
+	typ := reflect.TypeOf(prototype)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		panic("telebot: callback prototype must be a struct")
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			cb := c.Callback()
+			if cb == nil {
+				return next(c)
+			}
+
+			v := reflect.New(typ).Interface()
+			if err := cc.Unmarshal(cb.Data, v); err != nil {
+				return err
+			}
+
+			c.Set(callbackValueKey, v)
+			return next(c)
+		}
+	}
+}
+
+// CallbackValue returns the value decoded by the CallbackCodec
+// middleware, or nil if there is no such.
+func CallbackValue(c Context) interface{} {
+	return c.Get(callbackValueKey)
+}
+
+func packStruct(buf []byte, rv reflect.Value) ([]byte, error) {
+	t := rv.Type()
+	for i := 0; i < rv.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("callback") == "-" {
+			continue
+		}
+
+		var err error
+		if buf, err = packValue(buf, rv.Field(i)); err != nil {
+			return nil, fmt.Errorf("telebot: callback data field %s: %w", f.Name, err)
+		}
+	}
+	return buf, nil
+}
+
+func packValue(buf []byte, v reflect.Value) ([]byte, error) {
+	var tmp [binary.MaxVarintLen64]byte
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := binary.PutVarint(tmp[:], v.Int())
+		return append(buf, tmp[:n]...), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := binary.PutUvarint(tmp[:], v.Uint())
+		return append(buf, tmp[:n]...), nil
+	case reflect.Float32:
+		binary.BigEndian.PutUint32(tmp[:], math.Float32bits(float32(v.Float())))
+		return append(buf, tmp[:4]...), nil
+	case reflect.Float64:
+		binary.BigEndian.PutUint64(tmp[:], math.Float64bits(v.Float()))
+		return append(buf, tmp[:8]...), nil
+	case reflect.String:
+		n := binary.PutUvarint(tmp[:], uint64(v.Len()))
+		return append(append(buf, tmp[:n]...), v.String()...), nil
+	case reflect.Struct:
+		return packStruct(buf, v)
+	}
+	return nil, fmt.Errorf("unsupported kind %s", v.Kind())
+}
+
+func unpackStruct(buf []byte, rv reflect.Value) ([]byte, error) {
+	t := rv.Type()
+	for i := 0; i < rv.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("callback") == "-" {
+			continue
+		}
+
+		var err error
+		if buf, err = unpackValue(buf, rv.Field(i)); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func unpackValue(buf []byte, v reflect.Value) ([]byte, error) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if len(buf) < 1 || buf[0] > 1 {
+			return nil, ErrBadCallbackData
+		}
+		v.SetBool(buf[0] == 1)
+		return buf[1:], nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, n := binary.Varint(buf)
+		if n <= 0 || v.OverflowInt(x) {
+			return nil, ErrBadCallbackData
+		}
+		v.SetInt(x)
+		return buf[n:], nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, n := binary.Uvarint(buf)
+		if n <= 0 || v.OverflowUint(x) {
+			return nil, ErrBadCallbackData
+		}
+		v.SetUint(x)
+		return buf[n:], nil
+	case reflect.Float32:
+		if len(buf) < 4 {
+			return nil, ErrBadCallbackData
+		}
+		v.SetFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(buf))))
+		return buf[4:], nil
+	case reflect.Float64:
+		if len(buf) < 8 {
+			return nil, ErrBadCallbackData
+		}
+		v.SetFloat(math.Float64frombits(binary.BigEndian.Uint64(buf)))
+		return buf[8:], nil
+	case reflect.String:
+		l, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < l {
+			return nil, ErrBadCallbackData
+		}
+		v.SetString(string(buf[n : n+int(l)]))
+		return buf[n+int(l):], nil
+	case reflect.Struct:
+		return unpackStruct(buf, v)
+	}
+	return nil, ErrBadCallbackData
+}
+
+// CallbackMemoryStore keeps the callback payloads in memory.
+// They are lost once the process exits.
+type CallbackMemoryStore struct {
+	mu   sync.RWMutex
+	data map[string]string
+}
+
+// NewCallbackMemoryStore returns a new empty in-memory store.
+func NewCallbackMemoryStore() *CallbackMemoryStore {
+	return &CallbackMemoryStore{data: make(map[string]string)}
+}
+
+// Load implements CallbackStore.
+func (s *CallbackMemoryStore) Load(key string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.data[key]
+	return data, ok, nil
+}
+
+// Save implements CallbackStore.
+func (s *CallbackMemoryStore) Save(key, data string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = data
+	return nil
+}
@@ -0,0 +1,112 @@
+package telebot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCallbackData struct {
+	ItemID  int64
+	Count   uint8
+	Gift    bool
+	Price   float64
+	Comment string
+	Skipped string `callback:"-"`
+	Nested  struct{ Page int }
+}
+
+func (d *testCallbackData) Validate() error {
+	if d.Count == 0 {
+		return errors.New("count is zero")
+	}
+	return nil
+}
+
+func TestCallbackCodec(t *testing.T) {
+	var codec CallbackCodec
+
+	in := testCallbackData{ItemID: -42, Count: 3, Gift: true, Price: 9.99, Comment: "hi", Skipped: "x"}
+	in.Nested.Page = 7
+
+	btn, err := codec.Btn("Buy", "buy", in)
+	require.NoError(t, err)
+	assert.Equal(t, "buy", btn.Unique)
+	assert.LessOrEqual(t, len(btn.CallbackUnique()+"|"+btn.Data), 64)
+
+	var out testCallbackData
+	require.NoError(t, codec.Unmarshal(btn.Data, &out))
+	in.Skipped = ""
+	assert.Equal(t, in, out)
+
+	// validation
+	data, err := codec.Marshal("buy", testCallbackData{})
+	require.NoError(t, err)
+	assert.EqualError(t, codec.Unmarshal(data, &out), "count is zero")
+
+	// tampered data
+	assert.Equal(t, ErrBadCallbackData, codec.Unmarshal(btn.Data+"AA", &out))
+	assert.Equal(t, ErrBadCallbackData, codec.Unmarshal("!", &out))
+
+	// oversized payload
+	in.Comment = strings.Repeat("long", 20)
+	_, err = codec.Marshal("buy", in)
+	assert.Equal(t, ErrCallbackDataTooLong, err)
+
+	codec.Store = NewCallbackMemoryStore()
+	data, err = codec.Marshal("buy", in)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(data, "#"))
+
+	out = testCallbackData{}
+	require.NoError(t, codec.Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+
+	assert.Equal(t, ErrCallbackDataExpired, codec.Unmarshal("#missing", &out))
+
+	// room for the signature
+	var small struct{ S string }
+	small.S = strings.Repeat("x", 40)
+
+	codec = CallbackCodec{}
+	data, err = codec.Marshal("buy", small)
+	require.NoError(t, err)
+
+	codec.Signed = true
+	_, err = codec.Marshal("buy", small)
+	assert.Equal(t, ErrCallbackDataTooLong, err)
+}
+
+func TestCallbackCodecMiddleware(t *testing.T) {
+	var handlerErr error
+	b, err := NewBot(Settings{
+		Synchronous: true,
+		Offline:     true,
+		OnError:     func(err error, c Context) { handlerErr = err },
+	})
+	require.NoError(t, err)
+
+	var codec CallbackCodec
+	btn, err := codec.Btn("Buy", "buy", testCallbackData{ItemID: 1, Count: 2})
+	require.NoError(t, err)
+
+	var got *testCallbackData
+	b.Handle(&btn, func(c Context) error {
+		got = CallbackValue(c).(*testCallbackData)
+		return nil
+	}, codec.Middleware(testCallbackData{}))
+
+	b.ProcessUpdate(Update{Callback: &Callback{Data: "\fbuy|" + btn.Data}})
+	require.NoError(t, handlerErr)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(1), got.ItemID)
+	assert.Equal(t, uint8(2), got.Count)
+
+	got = nil
+	b.ProcessUpdate(Update{Callback: &Callback{Data: "\fbuy|forged"}})
+	assert.Error(t, handlerErr)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,123 @@
+package telebot
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCallbackForged is passed to the OnError when the signature
+// of the callback data doesn't match or is expired.
+var ErrCallbackForged = errors.New("telebot: callback data signature mismatch")
+
+// callbackSigner signs the inline buttons data, so the callbacks
+// forged by a bad client can be told apart.
+type callbackSigner struct {
+	key []byte
+	ttl time.Duration
+}
+
+// The signature is truncated to 8 bytes to fit into the 64 bytes limit.
+const callbackSigLen = 8
+
+// callbackSigOverhead is the most the signature adds to the data:
+// "|<timestamp>.<signature>", with up to 7 digits of the timestamp.
+const callbackSigOverhead = 1 + 7 + 1 + 11
+
+func newCallbackSigner(token string, secret []byte, ttl time.Duration) *callbackSigner {
+	if len(secret) == 0 {
+		secret = hmacSHA256([]byte("CallbackData"), []byte(token))
+	}
+	return &callbackSigner{key: secret, ttl: ttl}
+}
+
+// sign returns the signature of the data sent to the chat, which is
+// a chat ID, a @username or empty for the inline messages.
+func (s *callbackSigner) sign(chat, data, ts string) string {
+	mac := hmacSHA256(s.key, []byte(chat+"\n"+data+"\n"+ts))
+	return base64.RawURLEncoding.EncodeToString(mac[:callbackSigLen])
+}
+
+// signButtons appends the signature to the data of the buttons
+// processed by processButtons: "\f<unique>|<data>|<signature>".
+// If the TTL is set, the signature is prefixed with the timestamp.
+// ErrCallbackDataTooLong is returned if the signed data doesn't fit.
+func (s *callbackSigner) signButtons(chat string, keys [][]InlineButton) error {
+	var ts string
+	if s.ttl > 0 {
+		ts = strconv.FormatInt(time.Now().Unix(), 36)
+	}
+
+	for i := range keys {
+		for j := range keys[i] {
+			key := &keys[i][j]
+			if key.Unique == "" || !strings.HasPrefix(key.Data, "\f") {
+				continue
+			}
+
+			sig := s.sign(chat, key.Data, ts)
+			if ts != "" {
+				sig = ts + "." + sig
+			}
+			key.Data += "|" + sig
+			if len(key.Data) > callbackDataLimit {
+				return ErrCallbackDataTooLong
+			}
+		}
+	}
+	return nil
+}
+
+// verify checks the signature of the callback data
+// and returns the data without it.
+func (s *callbackSigner) verify(cb *Callback) (string, error) {
+	i := strings.LastIndexByte(cb.Data, '|')
+	if i < 0 {
+		return "", ErrCallbackForged
+	}
+	data, sig := cb.Data[:i], cb.Data[i+1:]
+
+	var ts string
+	if s.ttl > 0 {
+		j := strings.IndexByte(sig, '.')
+		if j < 0 {
+			return "", ErrCallbackForged
+		}
+		ts, sig = sig[:j], sig[j+1:]
+
+		unix, err := strconv.ParseInt(ts, 36, 64)
+		if err != nil || time.Since(time.Unix(unix, 0)) > s.ttl {
+			return "", ErrCallbackForged
+		}
+	}
+
+	// The button is bound to the chat it was sent to.
+	var chats []string
+	if cb.IsInline() {
+		chats = []string{""}
+	} else if cb.Message != nil && cb.Message.Chat != nil {
+		chat := cb.Message.Chat
+		chats = []string{strconv.FormatInt(chat.ID, 10)}
+		if chat.Username != "" {
+			chats = append(chats, "@"+chat.Username)
+		}
+	}
+
+	for _, chat := range chats {
+		if hmac.Equal([]byte(sig), []byte(s.sign(chat, data, ts))) {
+			return data, nil
+		}
+	}
+	return "", ErrCallbackForged
+}
+
+// signButtons signs the buttons if the bot has SignCallbacks enabled.
+func (b *Bot) signButtons(chat string, keys [][]InlineButton) error {
+	if b.signer != nil {
+		return b.signer.signButtons(chat, keys)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package telebot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignCallbacks(t *testing.T) {
+	var markup ReplyMarkup
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		require.NoError(t, json.Unmarshal([]byte(params["reply_markup"]), &markup))
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	var handlerErr error
+	b, err := NewBot(Settings{
+		Token:         "123:secret",
+		URL:           srv.URL,
+		Offline:       true,
+		Synchronous:   true,
+		SignCallbacks: true,
+		OnError:       func(err error, c Context) { handlerErr = err },
+	})
+	require.NoError(t, err)
+
+	btn := markup.Data("Buy", "buy", "42")
+
+	var args []string
+	b.Handle(&btn, func(c Context) error {
+		args = c.Args()
+		return nil
+	})
+
+	_, err = b.Send(&Chat{ID: 1}, "text", &ReplyMarkup{InlineKeyboard: [][]InlineButton{{*btn.Inline()}}})
+	require.NoError(t, err)
+
+	data := markup.InlineKeyboard[0][0].Data
+	assert.LessOrEqual(t, len(data), 64)
+
+	chat := &Message{Chat: &Chat{ID: 1}}
+	b.ProcessUpdate(Update{Callback: &Callback{Data: data, Message: chat}})
+	assert.NoError(t, handlerErr)
+	assert.Equal(t, []string{"42"}, args)
+
+	// forged payload
+	args = nil
+	b.ProcessUpdate(Update{Callback: &Callback{Data: "\fbuy|43" + data[len("\fbuy|42"):], Message: chat}})
+	assert.Equal(t, ErrCallbackForged, handlerErr)
+	assert.Nil(t, args)
+
+	// unsigned payload
+	handlerErr = nil
+	b.ProcessUpdate(Update{Callback: &Callback{Data: "\fbuy|42", Message: chat}})
+	assert.Equal(t, ErrCallbackForged, handlerErr)
+
+	// replayed in another chat
+	handlerErr = nil
+	b.ProcessUpdate(Update{Callback: &Callback{Data: data, Message: &Message{Chat: &Chat{ID: 2}}}})
+	assert.Equal(t, ErrCallbackForged, handlerErr)
+
+	// the reused markup is signed once
+	rm := &ReplyMarkup{InlineKeyboard: [][]InlineButton{{*btn.Inline()}}}
+	for i := 0; i < 2; i++ {
+		_, err = b.EditReplyMarkup(chat, rm)
+		require.NoError(t, err)
+		assert.Equal(t, data, markup.InlineKeyboard[0][0].Data)
+	}
+	assert.Equal(t, "42", rm.InlineKeyboard[0][0].Data)
+
+	// no room for the signature
+	rm.InlineKeyboard[0][0].Data = strings.Repeat("x", 50)
+	_, err = b.EditReplyMarkup(chat, rm)
+	assert.Equal(t, ErrCallbackDataTooLong, err)
+}
+
+func TestSignCallbacksTTL(t *testing.T) {
+	s := newCallbackSigner("123:secret", nil, time.Minute)
+
+	keys := [][]InlineButton{{{Unique: "buy", Data: "\fbuy|42"}}}
+	s.signButtons("1", keys)
+
+	cb := &Callback{Data: keys[0][0].Data, Message: &Message{Chat: &Chat{ID: 1}}}
+	data, err := s.verify(cb)
+	require.NoError(t, err)
+	assert.Equal(t, "\fbuy|42", data)
+
+	ts := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 36)
+	cb.Data = "\fbuy|42|" + ts + "." + s.sign("1", "\fbuy|42", ts)
+	_, err = s.verify(cb)
+	assert.Equal(t, ErrCallbackForged, err)
+
+	// a custom secret
+	other := newCallbackSigner("123:secret", []byte("key"), time.Minute)
+	cb.Data = keys[0][0].Data
+	_, err = other.verify(cb)
+	assert.Equal(t, ErrCallbackForged, err)
+}
@@ -573,3 +573,22 @@ func (b *Bot) DeleteGroupStickerSet(chat *Chat) error {
 	_, err := b.Raw("deleteChatStickerSet", params)
 	return err
 }
+
+// SetUserEmojiStatus changes the emoji status for the given user that
+// previously allowed the bot to manage their emoji status via the Mini App.
+// Pass an empty emoji ID to remove the status, and a zero expiration
+// for the status to never expire.
+func (b *Bot) SetUserEmojiStatus(user *User, emojiID string, expirationUnixtime int64) error {
+	params := map[string]string{
+		"user_id": user.Recipient(),
+	}
+	if emojiID != "" {
+		params["emoji_status_custom_emoji_id"] = emojiID
+	}
+	if expirationUnixtime != 0 {
+		params["emoji_status_expiration_date"] = strconv.FormatInt(expirationUnixtime, 10)
+	}
+
+	_, err := b.Raw("setUserEmojiStatus", params)
+	return err
+}
@@ -0,0 +1,168 @@
+package telebot
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Checklist describes a checklist.
+type Checklist struct {
+	// Title of the checklist.
+	Title string `json:"title"`
+
+	// (Optional) Special entities that appear in the checklist title.
+	TitleEntities Entities `json:"title_entities,omitempty"`
+
+	// List of tasks in the checklist.
+	Tasks []ChecklistTask `json:"tasks"`
+
+	// (Optional) True, if users other than the creator of the list can add tasks to the list.
+	OthersCanAddTasks bool `json:"others_can_add_tasks,omitempty"`
+
+	// (Optional) True, if users other than the creator of the list can mark tasks as done or not done.
+	OthersCanMarkTasksAsDone bool `json:"others_can_mark_tasks_as_done,omitempty"`
+}
+
+// ChecklistTask describes a task in a checklist.
+type ChecklistTask struct {
+	// Unique identifier of the task.
+	ID int `json:"id"`
+
+	// Text of the task.
+	Text string `json:"text"`
+
+	// (Optional) Special entities that appear in the task text.
+	TextEntities Entities `json:"text_entities,omitempty"`
+
+	// (Optional) User that completed the task; omitted if the task wasn't completed.
+	CompletedBy *User `json:"completed_by_user,omitempty"`
+
+	// (Optional) Point in time (Unix timestamp) when the task was completed;
+	// 0 if the task wasn't completed.
+	CompletionUnixtime int64 `json:"completion_date,omitempty"`
+}
+
+// CompletionDate returns the moment of the task completion in local time.
+func (t *ChecklistTask) CompletionDate() time.Time {
+	return time.Unix(t.CompletionUnixtime, 0)
+}
+
+// ChecklistTasksDone describes a service message about checklist tasks
+// marked as done or not done.
+type ChecklistTasksDone struct {
+	// (Optional) Message containing the checklist whose tasks were marked
+	// as done or not done. Note that the Message object in this field will
+	// not contain the ReplyTo field even if it itself is a reply.
+	ChecklistMessage *Message `json:"checklist_message,omitempty"`
+
+	// (Optional) Identifiers of the tasks that were marked as done.
+	MarkedAsDone []int `json:"marked_as_done_task_ids,omitempty"`
+
+	// (Optional) Identifiers of the tasks that were marked as not done.
+	MarkedAsNotDone []int `json:"marked_as_not_done_task_ids,omitempty"`
+}
+
+// ChecklistTasksAdded describes a service message about tasks added to a checklist.
+type ChecklistTasksAdded struct {
+	// (Optional) Message containing the checklist to which the tasks were added.
+	// Note that the Message object in this field will not contain the ReplyTo
+	// field even if it itself is a reply.
+	ChecklistMessage *Message `json:"checklist_message,omitempty"`
+
+	// List of tasks added to the checklist.
+	Tasks []ChecklistTask `json:"tasks"`
+}
+
+// InputChecklist describes a checklist to create.
+// It can be sent on behalf of a connected business account only,
+// so make sure to pass SendOptions.BusinessConnectionID.
+type InputChecklist struct {
+	// Title of the checklist; 1-255 characters after entities parsing.
+	Title string `json:"title"`
+
+	// (Optional) Mode for parsing entities in the title.
+	ParseMode ParseMode `json:"parse_mode,omitempty"`
+
+	// (Optional) Special entities that appear in the title,
+	// which can be specified instead of ParseMode.
+	TitleEntities Entities `json:"title_entities,omitempty"`
+
+	// List of 1-30 tasks in the checklist.
+	Tasks []InputChecklistTask `json:"tasks"`
+
+	// (Optional) Pass true if other users can add tasks to the checklist.
+	OthersCanAddTasks bool `json:"others_can_add_tasks,omitempty"`
+
+	// (Optional) Pass true if other users can mark tasks as done or not done in the checklist.
+	OthersCanMarkTasksAsDone bool `json:"others_can_mark_tasks_as_done,omitempty"`
+}
+
+// InputChecklistTask describes a task to add to a checklist.
+type InputChecklistTask struct {
+	// Unique identifier of the task; must be positive and unique
+	// among all task identifiers currently present in the checklist.
+	ID int `json:"id"`
+
+	// Text of the task; 1-100 characters after entities parsing.
+	Text string `json:"text"`
+
+	// (Optional) Mode for parsing entities in the text.
+	ParseMode ParseMode `json:"parse_mode,omitempty"`
+
+	// (Optional) Special entities that appear in the text,
+	// which can be specified instead of ParseMode.
+	TextEntities Entities `json:"text_entities,omitempty"`
+}
+
+// Send delivers checklist through bot b to recipient.
+func (c *InputChecklist) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
+	params := map[string]string{
+		"chat_id": to.Recipient(),
+	}
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
+
+	data, _ := json.Marshal(c)
+	params["checklist"] = string(data)
+
+	data, err := b.Raw("sendChecklist", params)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractMessage(data)
+}
+
+// EditChecklist edits a checklist sent on behalf of
+// the connected business account.
+func (b *Bot) EditChecklist(businessConnectionID string, msg Editable, c InputChecklist, markup ...*ReplyMarkup) (*Message, error) {
+	msgID, chatID := msg.MessageSig()
+	params := map[string]string{
+		"business_connection_id": businessConnectionID,
+		"chat_id":                strconv.FormatInt(chatID, 10),
+		"message_id":             msgID,
+	}
+
+	data, _ := json.Marshal(c)
+	params["checklist"] = string(data)
+
+	if len(markup) > 0 && markup[0] != nil {
+		// the caller's markup may be reused
+		rm := markup[0].copy()
+		processButtons(rm.InlineKeyboard)
+		if err := b.signButtons(params["chat_id"], rm.InlineKeyboard); err != nil {
+			return nil, err
+		}
+		data, _ := json.Marshal(rm)
+		params["reply_markup"] = string(data)
+	}
+
+	data, err := b.Raw("editMessageChecklist", params)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractMessage(data)
+}
@@ -0,0 +1,216 @@
+// Package command implements the declarative bot commands
+// with the typed arguments, usage and help texts.
+//
+// Example:
+//
+//	r := command.New(b)
+//
+//	r.Add(&command.Command{
+//		Name:        "ban",
+//		Description: "Ban the user",
+//		Params: []command.Param{
+//			command.User("user"),
+//			command.Duration("for").WithDefault(24 * time.Hour),
+//			command.Rest("reason").Optional(),
+//		},
+//		Scopes: []tele.CommandScope{{Type: tele.CommandScopeAllChatAdmin}},
+//		Handler: func(c tele.Context) error {
+//			args := command.Get(c)
+//			return ban(c, args.User("user"), args.Duration("for"), args.String("reason"))
+//		},
+//	})
+//
+//	r.Add(&command.Command{
+//		Name:        "help",
+//		Description: "Show the commands",
+//		Params:      []command.Param{command.String("command").Optional()},
+//		Handler:     r.Help,
+//	})
+//
+//	if err := r.SetCommands(b); err != nil {
+//		log.Fatal(err)
+//	}
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// Router is implemented by both tele.Bot and tele.Group.
+type Router interface {
+	Handle(endpoint interface{}, h tele.HandlerFunc, m ...tele.MiddlewareFunc)
+}
+
+// Command is a bot command with the typed parameters.
+type Command struct {
+	// Name of the command without the slash.
+	Name        string
+	Description string
+	Params      []Param
+
+	// Scopes the command is listed in, the default scope if empty.
+	Scopes []tele.CommandScope
+
+	// Hidden commands are handled, but not listed.
+	Hidden bool
+
+	Handler    tele.HandlerFunc
+	Middleware []tele.MiddlewareFunc
+}
+
+// Usage returns the command syntax, e.g. "/ban <user> [for] [reason...]".
+func (cmd *Command) Usage() string {
+	var sb strings.Builder
+	sb.WriteString("/" + cmd.Name)
+	for _, p := range cmd.Params {
+		sb.WriteString(" " + p.usage())
+	}
+	return sb.String()
+}
+
+// Help returns the usage, description and parameters of the command.
+func (cmd *Command) Help() string {
+	var sb strings.Builder
+	sb.WriteString(cmd.Usage())
+	if cmd.Description != "" {
+		sb.WriteString("\n" + cmd.Description)
+	}
+	for _, p := range cmd.Params {
+		if p.Description == "" && p.Default == nil {
+			continue
+		}
+		sb.WriteString("\n  " + p.Name)
+		if p.Description != "" {
+			sb.WriteString(" — " + p.Description)
+		}
+		if p.Default != nil {
+			sb.WriteString(fmt.Sprintf(" (default: %v)", p.Default))
+		}
+	}
+	return sb.String()
+}
+
+// parse parses the arguments of the message.
+func (cmd *Command) parse(m *tele.Message) (Args, error) {
+	toks := tokenize(m)
+	args := make(Args, len(cmd.Params))
+
+	for i := range cmd.Params {
+		p := &cmd.Params[i]
+
+		if p.Kind == KindRest {
+			if len(toks) > 0 {
+				args[p.Name] = strings.TrimSpace(m.Text[toks[0].pos:])
+				toks = nil
+				continue
+			}
+		} else if len(toks) > 0 {
+			v, err := p.parse(toks[0])
+			if err != nil {
+				return nil, &ArgError{Command: cmd, Param: p, Value: toks[0].text, Err: err}
+			}
+			args[p.Name] = v
+			toks = toks[1:]
+			continue
+		}
+
+		switch {
+		case p.Kind == KindUser && m.ReplyTo != nil && m.ReplyTo.Sender != nil:
+			args[p.Name] = m.ReplyTo.Sender
+		case p.Default != nil:
+			args[p.Name] = p.Default
+		case !p.optional:
+			return nil, &ArgError{Command: cmd, Param: p, Err: errMissing}
+		}
+	}
+
+	if len(toks) > 0 {
+		return nil, &ArgError{Command: cmd, Value: toks[0].text}
+	}
+	return args, nil
+}
+
+// Commands keeps the declared commands and handles them.
+type Commands struct {
+	// OnError is called with the *ArgError, when the arguments are invalid.
+	// By default, it replies with the error and the command usage.
+	OnError func(c tele.Context, err *ArgError) error
+
+	r    Router
+	cmds []*Command
+}
+
+// New returns the commands handled by the router.
+func New(r Router) *Commands {
+	return &Commands{r: r}
+}
+
+// Add declares and handles the command.
+func (cs *Commands) Add(cmd *Command) {
+	cs.cmds = append(cs.cmds, cmd)
+	cs.r.Handle("/"+cmd.Name, cs.handler(cmd), cmd.Middleware...)
+}
+
+// Lookup returns the command by its name, with or without the slash.
+func (cs *Commands) Lookup(name string) *Command {
+	name = strings.TrimPrefix(name, "/")
+	for _, cmd := range cs.cmds {
+		if strings.EqualFold(cmd.Name, name) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func (cs *Commands) handler(cmd *Command) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		m := c.Message()
+		if m == nil {
+			return cmd.Handler(c)
+		}
+
+		args, err := cmd.parse(m)
+		if err != nil {
+			argErr := err.(*ArgError)
+			if cs.OnError != nil {
+				return cs.OnError(c, argErr)
+			}
+			return c.Reply(argErr.Error() + "\n\nUsage: " + cmd.Usage())
+		}
+
+		c.Set(argsKey, args)
+		return cmd.Handler(c)
+	}
+}
+
+// HelpText returns the list of the listed commands with their usage
+// and description.
+func (cs *Commands) HelpText() string {
+	var lines []string
+	for _, cmd := range cs.cmds {
+		if cmd.Hidden {
+			continue
+		}
+		line := cmd.Usage()
+		if cmd.Description != "" {
+			line += " — " + cmd.Description
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Help is the handler sending the help text. Its first argument,
+// if any, is the name of the command to show the detailed help for.
+func (cs *Commands) Help(c tele.Context) error {
+	args := c.Args()
+	if len(args) > 0 {
+		if cmd := cs.Lookup(args[0]); cmd != nil && !cmd.Hidden {
+			return c.Send(cmd.Help())
+		}
+	}
+	return c.Send(cs.HelpText())
+}
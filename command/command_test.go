@@ -0,0 +1,199 @@
+package command
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+type request struct {
+	method string
+	params map[string]json.RawMessage
+}
+
+func (r request) text() string {
+	var s string
+	json.Unmarshal(r.params["text"], &s)
+	return s
+}
+
+func newTestBot(t *testing.T) (*tele.Bot, *[]request) {
+	var requests []request
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]json.RawMessage
+		json.NewDecoder(r.Body).Decode(&params)
+		method := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+		requests = append(requests, request{method, params})
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1,"chat":{"id":1}}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	b, err := tele.NewBot(tele.Settings{URL: srv.URL, Offline: true, Synchronous: true})
+	require.NoError(t, err)
+	return b, &requests
+}
+
+func message(text string, entities ...tele.MessageEntity) tele.Update {
+	return tele.Update{Message: &tele.Message{
+		Text:     text,
+		Entities: entities,
+		Chat:     &tele.Chat{ID: 1},
+		Sender:   &tele.User{ID: 2},
+	}}
+}
+
+func TestCommands(t *testing.T) {
+	b, requests := newTestBot(t)
+	cs := New(b)
+
+	var got Args
+	ban := &Command{
+		Name:        "ban",
+		Description: "Ban the user",
+		Params: []Param{
+			User("user"),
+			Duration("for").WithDefault(24 * time.Hour).Describe("ban duration"),
+			Enum("mode", "kick", "mute").Optional(),
+			Rest("reason").Optional(),
+		},
+		Handler: func(c tele.Context) error {
+			got = Get(c)
+			return nil
+		},
+	}
+	cs.Add(ban)
+
+	assert.Equal(t, "/ban <user> [for] [kick|mute] [reason...]", ban.Usage())
+	assert.Equal(t, "/ban <user> [for] [kick|mute] [reason...]\nBan the user\n  for — ban duration (default: 24h0m0s)", ban.Help())
+
+	b.ProcessUpdate(message("/ban 42"))
+	assert.Equal(t, Args{"user": &tele.User{ID: 42}, "for": 24 * time.Hour}, got)
+
+	b.ProcessUpdate(message("/ban @durov 2d MUTE  spam,  flood "))
+	assert.Equal(t, &tele.User{Username: "durov"}, got.User("user"))
+	assert.Equal(t, 48*time.Hour, got.Duration("for"))
+	assert.Equal(t, "mute", got.String("mode"))
+	assert.Equal(t, "spam,  flood", got.String("reason"))
+
+	// multi-line text
+	b.ProcessUpdate(message("/ban 42\n1h kick\nspam\nflood"))
+	assert.Equal(t, &tele.User{ID: 42}, got.User("user"))
+	assert.Equal(t, time.Hour, got.Duration("for"))
+	assert.Equal(t, "kick", got.String("mode"))
+	assert.Equal(t, "spam\nflood", got.String("reason"))
+
+	// text mention with the space in it
+	got = nil
+	b.ProcessUpdate(message("/ban John Smith 1h",
+		tele.MessageEntity{Type: tele.EntityTMention, Offset: 5, Length: 10, User: &tele.User{ID: 7}}))
+	assert.Equal(t, &tele.User{ID: 7}, got.User("user"))
+	assert.Equal(t, time.Hour, got.Duration("for"))
+
+	// the replied user
+	got = nil
+	u := message("/ban")
+	u.Message.ReplyTo = &tele.Message{Sender: &tele.User{ID: 9}}
+	b.ProcessUpdate(u)
+	assert.Equal(t, &tele.User{ID: 9}, got.User("user"))
+
+	got = nil
+	b.ProcessUpdate(message("/ban"))
+	assert.Nil(t, got)
+	last := (*requests)[len(*requests)-1]
+	assert.Equal(t, "sendMessage", last.method)
+	assert.Equal(t, "user is required\n\nUsage: /ban <user> [for] [kick|mute] [reason...]", last.text())
+
+	b.ProcessUpdate(message("/ban 1 soon"))
+	assert.Nil(t, got)
+	assert.Equal(t, `invalid for "soon": not a duration`, strings.Split((*requests)[len(*requests)-1].text(), "\n")[0])
+
+	var argErr *ArgError
+	cs.OnError = func(c tele.Context, err *ArgError) error {
+		argErr = err
+		return nil
+	}
+	b.ProcessUpdate(message("/ban 1 1h ban"))
+	require.NotNil(t, argErr)
+	assert.Equal(t, "mode", argErr.Param.Name)
+	assert.Equal(t, `invalid mode "ban": must be one of kick, mute`, argErr.Error())
+
+	cs.Add(&Command{Name: "add", Params: []Param{Int("a"), Int("b")}, Handler: func(c tele.Context) error {
+		got = Get(c)
+		return nil
+	}})
+	b.ProcessUpdate(message("/add 1 2 3"))
+	assert.Equal(t, "too many arguments: 3", argErr.Error())
+	b.ProcessUpdate(message("/add 1 2"))
+	assert.Equal(t, 3, got.Int("a")+got.Int("b"))
+
+	// text mention after a non-BMP character
+	cs.Add(&Command{Name: "gift", Params: []Param{String("what"), User("to")}, Handler: func(c tele.Context) error {
+		got = Get(c)
+		return nil
+	}})
+	b.ProcessUpdate(message("/gift 🙂 John Smith",
+		tele.MessageEntity{Type: tele.EntityTMention, Offset: 9, Length: 10, User: &tele.User{ID: 7}}))
+	assert.Equal(t, Args{"what": "🙂", "to": &tele.User{ID: 7}}, got)
+}
+
+func TestHelp(t *testing.T) {
+	b, requests := newTestBot(t)
+	cs := New(b)
+
+	noop := func(tele.Context) error { return nil }
+	cs.Add(&Command{Name: "start", Description: "Start the bot", Handler: noop})
+	cs.Add(&Command{Name: "secret", Hidden: true, Handler: noop})
+	cs.Add(&Command{Name: "help", Description: "Show the commands", Params: []Param{String("command").Optional()}, Handler: cs.Help})
+
+	assert.Equal(t, "/start — Start the bot\n/help [command] — Show the commands", cs.HelpText())
+
+	b.ProcessUpdate(message("/help"))
+	assert.Equal(t, "/start — Start the bot\n/help [command] — Show the commands", (*requests)[0].text())
+
+	b.ProcessUpdate(message("/help /start"))
+	assert.Equal(t, "/start\nStart the bot", (*requests)[1].text())
+}
+
+func TestSetCommands(t *testing.T) {
+	b, requests := newTestBot(t)
+	cs := New(b)
+
+	noop := func(tele.Context) error { return nil }
+	cs.Add(&Command{Name: "start", Description: "Start", Handler: noop})
+	cs.Add(&Command{Name: "ban", Description: "Ban", Handler: noop,
+		Scopes: []tele.CommandScope{{Type: tele.CommandScopeAllChatAdmin}}})
+	cs.Add(&Command{Name: "report", Description: "Report", Handler: noop,
+		Scopes: []tele.CommandScope{{Type: tele.CommandScopeAllGroupChats}}})
+	cs.Add(&Command{Name: "config", Description: "Config", Handler: noop,
+		Scopes: []tele.CommandScope{{Type: tele.CommandScopeChatAdmin, ChatID: -100}}})
+
+	require.NoError(t, cs.SetCommands(b, "en"))
+
+	lists := make(map[string][]string)
+	for _, r := range *requests {
+		require.Equal(t, "setMyCommands", r.method)
+		assert.Equal(t, `"en"`, string(r.params["language_code"]))
+
+		var cmds []tele.Command
+		require.NoError(t, json.Unmarshal(r.params["commands"], &cmds))
+		for _, cmd := range cmds {
+			lists[string(r.params["scope"])] = append(lists[string(r.params["scope"])], cmd.Text)
+		}
+	}
+
+	assert.Equal(t, map[string][]string{
+		`{"type":"default"}`:                            {"start"},
+		`{"type":"all_chat_administrators"}`:            {"start", "ban", "report"},
+		`{"type":"all_group_chats"}`:                    {"start", "report"},
+		`{"type":"chat_administrators","chat_id":-100}`: {"start", "ban", "report", "config"},
+	}, lists)
+}
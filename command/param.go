@@ -0,0 +1,317 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// Kind is a type of the command parameter.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindDuration
+	KindUser
+	KindEnum
+	KindRest
+)
+
+// Param is a positional parameter of the command. A parameter with
+// the default value, or marked optional, may be omitted, so only
+// the trailing parameters should be such.
+type Param struct {
+	Name        string
+	Description string
+	Kind        Kind
+
+	// Values lists the allowed values of the KindEnum parameter.
+	Values []string
+
+	// Default is the value of the omitted parameter.
+	Default interface{}
+
+	optional bool
+}
+
+// String returns the single-word parameter.
+func String(name string) Param {
+	return Param{Name: name, Kind: KindString}
+}
+
+// Int returns the integer parameter.
+func Int(name string) Param {
+	return Param{Name: name, Kind: KindInt}
+}
+
+// Duration returns the time.Duration parameter. Besides the Go syntax,
+// it accepts days and weeks, e.g. 1d or 2w.
+func Duration(name string) Param {
+	return Param{Name: name, Kind: KindDuration}
+}
+
+// User returns the *tele.User parameter, which is either a mention
+// or a numeric ID. When it's omitted in a reply, the sender of the
+// replied message is taken. A @username mention gives the user
+// with only the Username set, since bots can't resolve usernames.
+func User(name string) Param {
+	return Param{Name: name, Kind: KindUser}
+}
+
+// Enum returns the parameter, which is one of the values.
+// Values are matched case-insensitively.
+func Enum(name string, values ...string) Param {
+	return Param{Name: name, Kind: KindEnum, Values: values}
+}
+
+// Rest returns the parameter taking the rest of the line as is.
+// It must be the last one.
+func Rest(name string) Param {
+	return Param{Name: name, Kind: KindRest}
+}
+
+// Describe returns the parameter with the description.
+func (p Param) Describe(description string) Param {
+	p.Description = description
+	return p
+}
+
+// WithDefault returns the optional parameter with the default value.
+func (p Param) WithDefault(v interface{}) Param {
+	p.Default = v
+	p.optional = true
+	return p
+}
+
+// Optional returns the optional parameter without the default value.
+func (p Param) Optional() Param {
+	p.optional = true
+	return p
+}
+
+// IsOptional reports whether the parameter may be omitted.
+func (p Param) IsOptional() bool {
+	return p.optional || p.Default != nil
+}
+
+func (p Param) usage() string {
+	name := p.Name
+	switch p.Kind {
+	case KindEnum:
+		name = strings.Join(p.Values, "|")
+	case KindRest:
+		name += "..."
+	}
+	if p.IsOptional() {
+		return "[" + name + "]"
+	}
+	return "<" + name + ">"
+}
+
+var errNumber = errors.New("not a number")
+
+func (p Param) parse(tok token) (interface{}, error) {
+	switch p.Kind {
+	case KindInt:
+		n, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, errNumber
+		}
+		return n, nil
+	case KindDuration:
+		return parseDuration(tok.text)
+	case KindUser:
+		if tok.user != nil {
+			return tok.user, nil
+		}
+		if strings.HasPrefix(tok.text, "@") && len(tok.text) > 1 {
+			return &tele.User{Username: tok.text[1:]}, nil
+		}
+		id, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, errors.New("not a user")
+		}
+		return &tele.User{ID: id}, nil
+	case KindEnum:
+		for _, v := range p.Values {
+			if strings.EqualFold(v, tok.text) {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(p.Values, ", "))
+	}
+	return tok.text, nil
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, errors.New("not a duration")
+		}
+		return d, nil
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil {
+		return 0, errors.New("not a duration")
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// ArgError describes the invalid argument of the command.
+type ArgError struct {
+	Command *Command
+	Param   *Param // nil for the extra arguments
+	Value   string
+	Err     error
+}
+
+func (e *ArgError) Error() string {
+	if e.Param == nil {
+		return "too many arguments: " + e.Value
+	}
+	if e.Err == errMissing {
+		return e.Param.Name + " is required"
+	}
+	return fmt.Sprintf("invalid %s %q: %v", e.Param.Name, e.Value, e.Err)
+}
+
+func (e *ArgError) Unwrap() error {
+	return e.Err
+}
+
+var errMissing = errors.New("missing")
+
+// Args holds the parsed arguments of the command by the parameter names.
+// The omitted optional parameters without the default are absent.
+type Args map[string]interface{}
+
+// Has reports whether the argument is present.
+func (a Args) Has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+// String returns the string, enum or rest argument.
+func (a Args) String(name string) string {
+	s, _ := a[name].(string)
+	return s
+}
+
+// Int returns the integer argument.
+func (a Args) Int(name string) int {
+	n, _ := a[name].(int)
+	return n
+}
+
+// Duration returns the duration argument.
+func (a Args) Duration(name string) time.Duration {
+	d, _ := a[name].(time.Duration)
+	return d
+}
+
+// User returns the user argument.
+func (a Args) User(name string) *tele.User {
+	u, _ := a[name].(*tele.User)
+	return u
+}
+
+const argsKey = "command_args"
+
+// Get returns the arguments of the command being handled.
+func Get(c tele.Context) Args {
+	args, _ := c.Get(argsKey).(Args)
+	return args
+}
+
+// token is a word of the payload, or the whole text mention.
+type token struct {
+	text string
+	pos  int // byte offset in the message text
+	user *tele.User
+}
+
+// commandRx matches the /command[@bot] prefix of the message.
+var commandRx = regexp.MustCompile(`^/\w+(@\w+)?`)
+
+// tokenize splits the text after the command into the words, keeping
+// the text mentions whole, since they may contain spaces. Unlike the
+// payload, the text spans all the lines of the message.
+func tokenize(m *tele.Message) []token {
+	var start int
+	if loc := commandRx.FindStringIndex(m.Text); loc != nil {
+		start = loc[1]
+	}
+
+	type span struct {
+		start, end int
+		user       *tele.User
+	}
+	var mentions []span
+	for _, e := range m.Entities {
+		if e.Type != tele.EntityTMention || e.User == nil {
+			continue
+		}
+		s, t := byteOffset(m.Text, e.Offset), byteOffset(m.Text, e.Offset+e.Length)
+		if s >= start {
+			mentions = append(mentions, span{s, t, e.User})
+		}
+	}
+
+	var toks []token
+	i := start
+	for i < len(m.Text) {
+		if isSpace(m.Text[i]) {
+			i++
+			continue
+		}
+
+		if len(mentions) > 0 && mentions[0].start == i {
+			toks = append(toks, token{text: m.Text[i:mentions[0].end], pos: i, user: mentions[0].user})
+			i = mentions[0].end
+			mentions = mentions[1:]
+			continue
+		}
+
+		j := i
+		for j < len(m.Text) && !isSpace(m.Text[j]) {
+			j++
+		}
+		toks = append(toks, token{text: m.Text[i:j], pos: i})
+		i = j
+	}
+	return toks
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t' || c == '\r'
+}
+
+// byteOffset converts the UTF-16 offset into the byte one.
+func byteOffset(s string, offset int) int {
+	n := 0
+	for i, r := range s {
+		if n >= offset {
+			return i
+		}
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return len(s)
+}
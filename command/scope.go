@@ -0,0 +1,95 @@
+package command
+
+import (
+	tele "gopkg.in/telebot.v4"
+)
+
+// SetCommands registers the listed commands with Telegram for every
+// scope they're declared in. Telegram shows only the list of the most
+// specific scope matching the chat, so the list of each scope includes
+// the commands of the broader scopes covering it too.
+func (cs *Commands) SetCommands(api tele.API, opts ...interface{}) error {
+	scopes := []tele.CommandScope{{Type: tele.CommandScopeDefault}}
+	for _, cmd := range cs.cmds {
+		for _, scope := range cmd.Scopes {
+			if !containsScope(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	for _, scope := range scopes {
+		var list []tele.Command
+		for _, cmd := range cs.cmds {
+			if cmd.Hidden || !cs.listed(cmd, scope) {
+				continue
+			}
+			list = append(list, tele.Command{
+				Text:        cmd.Name,
+				Description: cmd.Description,
+			})
+		}
+
+		if len(list) == 0 {
+			continue
+		}
+		if err := api.SetCommands(append([]interface{}{list, scope}, opts...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cs *Commands) listed(cmd *Command, scope tele.CommandScope) bool {
+	if len(cmd.Scopes) == 0 {
+		return true
+	}
+	for _, s := range cmd.Scopes {
+		if covers(s, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsScope(scopes []tele.CommandScope, scope tele.CommandScope) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// covers reports whether the broad scope applies to
+// every chat and user the narrow one applies to.
+func covers(broad, narrow tele.CommandScope) bool {
+	if broad == narrow {
+		return true
+	}
+
+	// Private chats have positive IDs, groups negative ones.
+	group := narrow.ChatID < 0
+
+	switch broad.Type {
+	case tele.CommandScopeDefault:
+		return true
+	case tele.CommandScopeAllPrivateChats:
+		return narrow.Type == tele.CommandScopeChat && !group
+	case tele.CommandScopeAllGroupChats:
+		switch narrow.Type {
+		case tele.CommandScopeAllChatAdmin:
+			return true
+		case tele.CommandScopeChat, tele.CommandScopeChatAdmin, tele.CommandScopeChatMember:
+			return group
+		}
+	case tele.CommandScopeAllChatAdmin:
+		return narrow.Type == tele.CommandScopeChatAdmin
+	case tele.CommandScopeChat:
+		switch narrow.Type {
+		case tele.CommandScopeChatAdmin, tele.CommandScopeChatMember:
+			return narrow.ChatID == broad.ChatID
+		}
+	}
+	return false
+}
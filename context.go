@@ -1,6 +1,7 @@
 package telebot
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"sync"
@@ -23,8 +24,20 @@ func NewContext(b API, u Update) Context {
 // Context wraps an update and represents the context of current event.
 type Context interface {
 	// Bot returns the bot instance.
+	// Notice that it's not bound to StdContext, so the calls made through
+	// it directly are not aborted when the context is done.
 	Bot() API
 
+	// StdContext returns the standard context bound to the current update.
+	// All the API calls made through the Context methods use it, so once
+	// it's done, their in-flight requests are aborted.
+	// Returns context.Background() if no context was set.
+	StdContext() context.Context
+
+	// SetStdContext replaces the standard context bound to the current update.
+	// Useful for middleware, which attach deadlines or tracing data.
+	SetStdContext(ctx context.Context)
+
 	// Update returns the original update.
 	Update() Update
 
@@ -73,6 +86,12 @@ type Context interface {
 	// BoostRemoved returns the boost removed from a chat instance.
 	BoostRemoved() *BoostRemoved
 
+	// Reaction returns the message reaction change if such presented.
+	Reaction() *MessageReaction
+
+	// ReactionCount returns the anonymous reaction count change if such presented.
+	ReactionCount() *MessageReactionCount
+
 	// Sender returns the current recipient, depending on the context type.
 	// Returns nil if user is not presented.
 	Sender() *User
@@ -107,6 +126,10 @@ type Context interface {
 	// The message arguments split by space, while the callback's ones by a "|" symbol.
 	Args() []string
 
+	// Matches returns the submatches of the regexp endpoint, which the
+	// update is handled by, the whole match first. Returns nil otherwise.
+	Matches() []string
+
 	// Send sends a message to the current recipient.
 	// See Send from bot.go.
 	Send(what interface{}, opts ...interface{}) error
@@ -192,12 +215,46 @@ type nativeContext struct {
 	u     Update
 	lock  sync.RWMutex
 	store map[string]interface{}
+	ctx   context.Context
 }
 
 func (c *nativeContext) Bot() API {
 	return c.b
 }
 
+func (c *nativeContext) StdContext() context.Context {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+func (c *nativeContext) SetStdContext(ctx context.Context) {
+	if ctx == nil {
+		panic("telebot: nil context")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.ctx = ctx
+}
+
+// api returns the bot bound to the standard context, if such presented.
+// Custom API implementations are returned as is.
+func (c *nativeContext) api() API {
+	c.lock.RLock()
+	ctx := c.ctx
+	c.lock.RUnlock()
+
+	if b, ok := c.b.(*Bot); ok && ctx != nil {
+		return b.WithContext(ctx)
+	}
+	return c.b
+}
+
 func (c *nativeContext) Update() Update {
 	return c.u
 }
@@ -304,6 +361,14 @@ func (c *nativeContext) BoostRemoved() *BoostRemoved {
 	return c.u.BoostRemoved
 }
 
+func (c *nativeContext) Reaction() *MessageReaction {
+	return c.u.MessageReaction
+}
+
+func (c *nativeContext) ReactionCount() *MessageReactionCount {
+	return c.u.MessageReactionCount
+}
+
 func (c *nativeContext) Sender() *User {
 	switch {
 	case c.u.Callback != nil:
@@ -334,6 +399,10 @@ func (c *nativeContext) Sender() *User {
 		if b := c.u.BoostRemoved; b.Source != nil {
 			return b.Source.Booster
 		}
+	case c.u.MessageReaction != nil:
+		// User is nil when the reaction was left anonymously
+		// on behalf of the ActorChat.
+		return c.u.MessageReaction.User
 	}
 	return nil
 }
@@ -348,6 +417,10 @@ func (c *nativeContext) Chat() *Chat {
 		return c.u.ChatMember.Chat
 	case c.u.ChatJoinRequest != nil:
 		return c.u.ChatJoinRequest.Chat
+	case c.u.MessageReaction != nil:
+		return c.u.MessageReaction.Chat
+	case c.u.MessageReactionCount != nil:
+		return c.u.MessageReactionCount.Chat
 	default:
 		return nil
 	}
@@ -426,6 +499,11 @@ func (c *nativeContext) Args() []string {
 	return nil
 }
 
+func (c *nativeContext) Matches() []string {
+	matches, _ := c.Get(matchesKey).([]string)
+	return matches
+}
+
 func (c *nativeContext) ThreadID() int {
 	switch {
 	case c.Message() != nil:
@@ -437,7 +515,7 @@ func (c *nativeContext) ThreadID() int {
 
 func (c *nativeContext) Send(what interface{}, opts ...interface{}) error {
 	opts = c.inheritOpts(opts...)
-	_, err := c.b.Send(c.Recipient(), what, opts...)
+	_, err := c.replyAPI().Send(c.Recipient(), what, opts...)
 	return err
 }
 
@@ -472,7 +550,7 @@ func (c *nativeContext) inheritOpts(opts ...interface{}) []interface{} {
 func (c *nativeContext) SendAlbum(a Album, opts ...interface{}) error {
 	opts = c.inheritOpts(opts...)
 
-	_, err := c.b.SendAlbum(c.Recipient(), a, opts...)
+	_, err := c.api().SendAlbum(c.Recipient(), a, opts...)
 	return err
 }
 
@@ -482,12 +560,12 @@ func (c *nativeContext) Reply(what interface{}, opts ...interface{}) error {
 		return ErrBadContext
 	}
 	opts = c.inheritOpts(opts...)
-	_, err := c.b.Reply(msg, what, opts...)
+	_, err := c.replyAPI().Reply(msg, what, opts...)
 	return err
 }
 
 func (c *nativeContext) Forward(msg Editable, opts ...interface{}) error {
-	_, err := c.b.Forward(c.Recipient(), msg, opts...)
+	_, err := c.api().Forward(c.Recipient(), msg, opts...)
 	return err
 }
 
@@ -496,7 +574,7 @@ func (c *nativeContext) ForwardTo(to Recipient, opts ...interface{}) error {
 	if msg == nil {
 		return ErrBadContext
 	}
-	_, err := c.b.Forward(to, msg, opts...)
+	_, err := c.api().Forward(to, msg, opts...)
 	return err
 }
 
@@ -504,11 +582,11 @@ func (c *nativeContext) Edit(what interface{}, opts ...interface{}) error {
 	opts = c.inheritOpts(opts...)
 
 	if c.u.InlineResult != nil {
-		_, err := c.b.Edit(c.u.InlineResult, what, opts...)
+		_, err := c.api().Edit(c.u.InlineResult, what, opts...)
 		return err
 	}
 	if c.u.Callback != nil {
-		_, err := c.b.Edit(c.u.Callback, what, opts...)
+		_, err := c.api().Edit(c.u.Callback, what, opts...)
 		return err
 	}
 	return ErrBadContext
@@ -518,11 +596,11 @@ func (c *nativeContext) EditCaption(caption string, opts ...interface{}) error {
 	opts = c.inheritOpts(opts...)
 
 	if c.u.InlineResult != nil {
-		_, err := c.b.EditCaption(c.u.InlineResult, caption, opts...)
+		_, err := c.api().EditCaption(c.u.InlineResult, caption, opts...)
 		return err
 	}
 	if c.u.Callback != nil {
-		_, err := c.b.EditCaption(c.u.Callback, caption, opts...)
+		_, err := c.api().EditCaption(c.u.Callback, caption, opts...)
 		return err
 	}
 	return ErrBadContext
@@ -549,12 +627,18 @@ func (c *nativeContext) Delete() error {
 	if msg == nil {
 		return ErrBadContext
 	}
-	return c.b.Delete(msg)
+	return c.api().Delete(msg)
 }
 
 func (c *nativeContext) DeleteAfter(d time.Duration) *time.Timer {
+	// The deletion outlives the handler, so it's not
+	// bound to the standard context of the update.
 	return time.AfterFunc(d, func() {
-		if err := c.Delete(); err != nil {
+		err := ErrBadContext
+		if msg := c.Message(); msg != nil {
+			err = c.b.Delete(msg)
+		}
+		if err != nil {
 			if b, ok := c.b.(*Bot); ok {
 				b.OnError(err, c)
 			}
@@ -563,28 +647,28 @@ func (c *nativeContext) DeleteAfter(d time.Duration) *time.Timer {
 }
 
 func (c *nativeContext) Notify(action ChatAction) error {
-	return c.b.Notify(c.Recipient(), action, c.ThreadID())
+	return c.api().Notify(c.Recipient(), action, c.ThreadID())
 }
 
 func (c *nativeContext) Ship(what ...interface{}) error {
 	if c.u.ShippingQuery == nil {
 		return errors.New("telebot: context shipping query is nil")
 	}
-	return c.b.Ship(c.u.ShippingQuery, what...)
+	return c.api().Ship(c.u.ShippingQuery, what...)
 }
 
 func (c *nativeContext) Accept(errorMessage ...string) error {
 	if c.u.PreCheckoutQuery == nil {
 		return errors.New("telebot: context pre checkout query is nil")
 	}
-	return c.b.Accept(c.u.PreCheckoutQuery, errorMessage...)
+	return c.api().Accept(c.u.PreCheckoutQuery, errorMessage...)
 }
 
 func (c *nativeContext) Respond(resp ...*CallbackResponse) error {
 	if c.u.Callback == nil {
 		return errors.New("telebot: context callback is nil")
 	}
-	return c.b.Respond(c.u.Callback, resp...)
+	return c.replyAPI().Respond(c.u.Callback, resp...)
 }
 
 func (c *nativeContext) RespondText(text string) error {
@@ -599,7 +683,7 @@ func (c *nativeContext) Answer(resp *QueryResponse) error {
 	if c.u.Query == nil {
 		return errors.New("telebot: context inline query is nil")
 	}
-	return c.b.Answer(c.u.Query, resp)
+	return c.api().Answer(c.u.Query, resp)
 }
 
 func (c *nativeContext) Set(key string, value interface{}) {
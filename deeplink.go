@@ -0,0 +1,240 @@
+package telebot
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Start parameters consist of A-Z, a-z, 0-9, _ and -, and are limited
+// by Telegram to 64 characters, or 512 for the Mini App links.
+const (
+	startParamLimit    = 64
+	startAppParamLimit = 512
+)
+
+// Deep link errors.
+var (
+	ErrStartParamTooLong = errors.New("telebot: start parameter is too long")
+	ErrBadStartParam     = errors.New("telebot: bad start parameter")
+)
+
+// DeepLinkKind is the query parameter of the deep link,
+// which defines where the link leads.
+type DeepLinkKind = string
+
+const (
+	// DeepLinkStart opens the private chat with the bot.
+	DeepLinkStart DeepLinkKind = "start"
+
+	// DeepLinkGroup adds the bot to the group.
+	DeepLinkGroup DeepLinkKind = "startgroup"
+
+	// DeepLinkApp opens the main Mini App of the bot.
+	DeepLinkApp DeepLinkKind = "startapp"
+
+	// DeepLinkAttach opens the bot's attachment menu.
+	DeepLinkAttach DeepLinkKind = "startattach"
+)
+
+var startPrefixRx = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// EncodeStartParam returns the start parameter of the link of the kind
+// with the prefix and v packed as CallbackCodec does, e.g. "ref_KgE".
+// A nil v gives the prefix only. The prefix consists of letters and digits.
+func EncodeStartParam(kind DeepLinkKind, prefix string, v interface{}) (string, error) {
+	if !startPrefixRx.MatchString(prefix) {
+		return "", fmt.Errorf("telebot: bad start parameter prefix %q", prefix)
+	}
+	if v == nil {
+		return prefix, nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return "", fmt.Errorf("telebot: start parameter must be a struct, got %T", v)
+	}
+
+	buf, err := packStruct(nil, rv)
+	if err != nil {
+		return "", err
+	}
+
+	limit := startParamLimit
+	if kind == DeepLinkApp {
+		limit = startAppParamLimit
+	}
+
+	param := prefix + "_" + base64.RawURLEncoding.EncodeToString(buf)
+	if len(param) > limit {
+		return "", ErrStartParamTooLong
+	}
+	return param, nil
+}
+
+// DecodeStartParam splits the start parameter into the prefix and
+// the packed payload, and decodes the latter into v, if it's not nil.
+func DecodeStartParam(param string, v interface{}) (prefix string, err error) {
+	prefix, data := param, ""
+	if i := strings.IndexByte(param, '_'); i >= 0 {
+		prefix, data = param[:i], param[i+1:]
+	}
+	if v == nil {
+		return prefix, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return "", fmt.Errorf("telebot: start parameter must be a pointer to struct, got %T", v)
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return "", ErrBadStartParam
+	}
+
+	rest, err := unpackStruct(buf, rv.Elem())
+	if err != nil || len(rest) > 0 {
+		return "", ErrBadStartParam
+	}
+
+	if validator, ok := v.(interface{ Validate() error }); ok {
+		return prefix, validator.Validate()
+	}
+	return prefix, nil
+}
+
+// DeepLink returns the t.me link to the bot with the start parameter,
+// which is passed as is, e.g. built with EncodeStartParam.
+func DeepLink(username string, kind DeepLinkKind, param string) string {
+	link := "https://t.me/" + username
+	if kind == DeepLinkApp && param == "" {
+		return link + "?startapp"
+	}
+	return link + "?" + kind + "=" + url.QueryEscape(param)
+}
+
+// DeepLink returns the link to the bot with the start parameter
+// built of the prefix and v. See EncodeStartParam.
+func (b *Bot) DeepLink(kind DeepLinkKind, prefix string, v interface{}) (string, error) {
+	param, err := EncodeStartParam(kind, prefix, v)
+	if err != nil {
+		return "", err
+	}
+	return DeepLink(b.Me.Username, kind, param), nil
+}
+
+const startValueKey = "start_value"
+
+type startRoute struct {
+	prototype reflect.Type
+	handler   HandlerFunc
+}
+
+// StartRouter dispatches the /start commands by the prefix
+// of their start parameters.
+//
+//	type Referral struct {
+//		UserID int64
+//	}
+//
+//	link, err := b.DeepLink(tele.DeepLinkStart, "ref", Referral{UserID: 42})
+//
+//	sr := tele.NewStartRouter()
+//	sr.Handle("ref", Referral{}, func(c tele.Context) error {
+//		ref := tele.StartValue(c).(*Referral)
+//		...
+//	})
+//	sr.Default = onStart
+//
+//	b.Handle("/start", sr.Handler)
+type StartRouter struct {
+	// Default handles the /start without a parameter, or with
+	// the unknown prefix.
+	Default HandlerFunc
+
+	// OnError handles the parameter, which can't be decoded. If it's
+	// nil, such parameters are handled by Default.
+	OnError func(c Context, err error) error
+
+	mu     sync.RWMutex
+	routes map[string]startRoute
+}
+
+// NewStartRouter returns a new empty router.
+func NewStartRouter() *StartRouter {
+	return &StartRouter{routes: make(map[string]startRoute)}
+}
+
+// Handle routes the start parameters with the prefix to the handler.
+// The payload is decoded into a new value of the prototype's type, which
+// is then accessed with StartValue. A nil prototype skips the decoding.
+func (sr *StartRouter) Handle(prefix string, prototype interface{}, h HandlerFunc, m ...MiddlewareFunc) {
+	if !startPrefixRx.MatchString(prefix) {
+		panic("telebot: bad start parameter prefix " + prefix)
+	}
+
+	var typ reflect.Type
+	if prototype != nil {
+		typ = reflect.TypeOf(prototype)
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			panic("telebot: start parameter prototype must be a struct")
+		}
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.routes[prefix] = startRoute{prototype: typ, handler: applyMiddleware(h, m...)}
+}
+
+// Handler is the handler of the /start command.
+func (sr *StartRouter) Handler(c Context) error {
+	var param string
+	if m := c.Message(); m != nil {
+		param = strings.TrimSpace(m.Payload)
+	}
+
+	prefix, _ := DecodeStartParam(param, nil)
+
+	sr.mu.RLock()
+	route, ok := sr.routes[prefix]
+	sr.mu.RUnlock()
+
+	if param == "" || !ok {
+		return sr.fallback(c)
+	}
+
+	if route.prototype != nil {
+		v := reflect.New(route.prototype).Interface()
+		if _, err := DecodeStartParam(param, v); err != nil {
+			if sr.OnError != nil {
+				return sr.OnError(c, err)
+			}
+			return sr.fallback(c)
+		}
+		c.Set(startValueKey, v)
+	}
+
+	return route.handler(c)
+}
+
+func (sr *StartRouter) fallback(c Context) error {
+	if sr.Default == nil {
+		return nil
+	}
+	return sr.Default(c)
+}
+
+// StartValue returns the start parameter payload decoded
+// by the StartRouter, or nil if there is no such.
+func StartValue(c Context) interface{} {
+	return c.Get(startValueKey)
+}
@@ -0,0 +1,110 @@
+package telebot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testReferral struct {
+	UserID   int64
+	Campaign string
+}
+
+func TestStartParam(t *testing.T) {
+	param, err := EncodeStartParam(DeepLinkStart, "ref", testReferral{UserID: 42, Campaign: "spring"})
+	require.NoError(t, err)
+	assert.Regexp(t, `^ref_[A-Za-z0-9_-]+$`, param)
+
+	var ref testReferral
+	prefix, err := DecodeStartParam(param, &ref)
+	require.NoError(t, err)
+	assert.Equal(t, "ref", prefix)
+	assert.Equal(t, testReferral{UserID: 42, Campaign: "spring"}, ref)
+
+	param, err = EncodeStartParam(DeepLinkStart, "onboarding", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "onboarding", param)
+
+	_, err = DecodeStartParam("onboarding", &ref)
+	assert.Equal(t, ErrBadStartParam, err)
+	_, err = DecodeStartParam("ref_!!", &ref)
+	assert.Equal(t, ErrBadStartParam, err)
+
+	_, err = EncodeStartParam(DeepLinkStart, "ref_", nil)
+	assert.Error(t, err)
+	_, err = EncodeStartParam(DeepLinkStart, "ref", testReferral{Campaign: strings.Repeat("x", 60)})
+	assert.Equal(t, ErrStartParamTooLong, err)
+	_, err = EncodeStartParam(DeepLinkApp, "ref", testReferral{Campaign: strings.Repeat("x", 60)})
+	assert.NoError(t, err)
+	_, err = EncodeStartParam(DeepLinkApp, "ref", testReferral{Campaign: strings.Repeat("x", 400)})
+	assert.Equal(t, ErrStartParamTooLong, err)
+
+	assert.Equal(t, "https://t.me/bot?start=ref_KgE", DeepLink("bot", DeepLinkStart, "ref_KgE"))
+	assert.Equal(t, "https://t.me/bot?startgroup=admin", DeepLink("bot", DeepLinkGroup, "admin"))
+	assert.Equal(t, "https://t.me/bot?startapp", DeepLink("bot", DeepLinkApp, ""))
+	assert.Equal(t, "https://t.me/bot?startattach=x", DeepLink("bot", DeepLinkAttach, "x"))
+}
+
+func TestStartRouter(t *testing.T) {
+	b, err := NewBot(Settings{Synchronous: true, Offline: true})
+	require.NoError(t, err)
+	b.Me.Username = "bot"
+
+	link, err := b.DeepLink(DeepLinkStart, "ref", testReferral{UserID: 42})
+	require.NoError(t, err)
+	param := strings.TrimPrefix(link, "https://t.me/bot?start=")
+
+	_, err = b.DeepLink(DeepLinkApp, "ref", testReferral{Campaign: strings.Repeat("x", 60)})
+	require.NoError(t, err)
+
+	var trace []string
+	sr := NewStartRouter()
+	sr.Default = func(c Context) error {
+		trace = append(trace, "default:"+c.Message().Payload)
+		return nil
+	}
+	sr.Handle("ref", testReferral{}, func(c Context) error {
+		trace = append(trace, "ref:"+c.Get("mw").(string))
+		assert.Equal(t, &testReferral{UserID: 42}, StartValue(c))
+		return nil
+	}, func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			c.Set("mw", "ok")
+			return next(c)
+		}
+	})
+	sr.Handle("help", nil, func(c Context) error {
+		trace = append(trace, "help")
+		assert.Nil(t, StartValue(c))
+		return nil
+	})
+	b.Handle("/start", sr.Handler)
+
+	start := func(text string) {
+		b.ProcessUpdate(Update{Message: &Message{Text: text, Chat: &Chat{ID: 1}}})
+	}
+
+	start("/start " + param)
+	start("/start help")
+	start("/start")
+	start("/start unknown_AA")
+	start("/start ref_!!")
+
+	sr.OnError = func(c Context, err error) error {
+		trace = append(trace, "error:"+err.Error())
+		return nil
+	}
+	start("/start ref_!!")
+
+	assert.Equal(t, []string{
+		"ref:ok",
+		"help",
+		"default:",
+		"default:unknown_AA",
+		"default:ref_!!",
+		"error:" + ErrBadStartParam.Error(),
+	}, trace)
+}
@@ -0,0 +1,326 @@
+// Package format builds formatted message texts without manual
+// escaping, either as a plain text with entities or as a markup
+// for the chosen parse mode.
+//
+// Example:
+//
+//	text := format.New().
+//		Bold("Hello, ", format.Mention(user)).
+//		Text("! Your code is ").
+//		Code(code)
+//
+//	// sends the text with entities, no parse mode is needed
+//	c.Send(text)
+//
+//	// or renders an escaped HTML
+//	html := text.Render(tele.ModeHTML)
+//
+// The received messages can be formatted back with FromMessage,
+// and the marked up texts are parsed into entities with Parse.
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// Node is a piece of the formatted text. It's either a plain text
+// or an entity wrapping the nested nodes.
+type Node struct {
+	typ      tele.EntityType
+	text     string
+	children []Node
+	url      string
+	user     *tele.User
+	language string
+	emojiID  string
+}
+
+// Text returns a plain text node.
+func Text(s string) Node {
+	return Node{text: s}
+}
+
+// Bold returns a bold node.
+func Bold(parts ...interface{}) Node {
+	return wrap(tele.EntityBold, parts)
+}
+
+// Italic returns an italic node.
+func Italic(parts ...interface{}) Node {
+	return wrap(tele.EntityItalic, parts)
+}
+
+// Underline returns an underlined node.
+func Underline(parts ...interface{}) Node {
+	return wrap(tele.EntityUnderline, parts)
+}
+
+// Strikethrough returns a strikethrough node.
+func Strikethrough(parts ...interface{}) Node {
+	return wrap(tele.EntityStrikethrough, parts)
+}
+
+// Spoiler returns a spoiler node.
+func Spoiler(parts ...interface{}) Node {
+	return wrap(tele.EntitySpoiler, parts)
+}
+
+// Blockquote returns a block quotation node.
+func Blockquote(parts ...interface{}) Node {
+	return wrap(tele.EntityBlockquote, parts)
+}
+
+// ExpandableBlockquote returns a block quotation node,
+// which is collapsed by default.
+func ExpandableBlockquote(parts ...interface{}) Node {
+	return wrap(tele.EntityEBlockquote, parts)
+}
+
+// Code returns an inline monowidth code node.
+func Code(s string) Node {
+	return Node{typ: tele.EntityCode, text: s}
+}
+
+// Pre returns a pre-formatted code block node
+// with an optional programming language.
+func Pre(s, language string) Node {
+	return Node{typ: tele.EntityCodeBlock, text: s, language: language}
+}
+
+// Link returns a clickable text URL node.
+// If there are no parts, the URL itself is used as a text.
+func Link(url string, parts ...interface{}) Node {
+	if len(parts) == 0 {
+		parts = []interface{}{url}
+	}
+	n := wrap(tele.EntityTextLink, parts)
+	n.url = url
+	return n
+}
+
+// Mention returns a mention node of the user without a username.
+// If there are no parts, the user's first name is used as a text.
+func Mention(user *tele.User, parts ...interface{}) Node {
+	if len(parts) == 0 {
+		parts = []interface{}{user.FirstName}
+	}
+	n := wrap(tele.EntityTMention, parts)
+	n.user = user
+	return n
+}
+
+// CustomEmoji returns a custom emoji node. The emoji
+// is shown in case the custom one can't be displayed.
+func CustomEmoji(emoji, id string) Node {
+	return Node{typ: tele.EntityCustomEmoji, text: emoji, emojiID: id}
+}
+
+func wrap(typ tele.EntityType, parts []interface{}) Node {
+	return Node{typ: typ, children: toNodes(parts)}
+}
+
+// toNodes converts the parts, which are either strings,
+// nodes or builders, to the list of nodes.
+func toNodes(parts []interface{}) []Node {
+	nodes := make([]Node, 0, len(parts))
+	for _, p := range parts {
+		switch v := p.(type) {
+		case string:
+			nodes = append(nodes, Text(v))
+		case Node:
+			nodes = append(nodes, v)
+		case []Node:
+			nodes = append(nodes, v...)
+		case *Builder:
+			nodes = append(nodes, v.nodes...)
+		default:
+			nodes = append(nodes, Text(fmt.Sprint(v)))
+		}
+	}
+	return nodes
+}
+
+// Builder composes the formatted text node by node.
+// Its methods accept strings, nodes or other builders as parts.
+type Builder struct {
+	nodes []Node
+}
+
+// New returns a builder with the given parts.
+func New(parts ...interface{}) *Builder {
+	return &Builder{nodes: toNodes(parts)}
+}
+
+// Append adds the parts as they are.
+func (b *Builder) Append(parts ...interface{}) *Builder {
+	b.nodes = append(b.nodes, toNodes(parts)...)
+	return b
+}
+
+// Text adds a plain text.
+func (b *Builder) Text(s string) *Builder {
+	return b.Append(Text(s))
+}
+
+// Textf adds a plain text formatted with fmt.Sprintf.
+func (b *Builder) Textf(format string, args ...interface{}) *Builder {
+	return b.Append(Text(fmt.Sprintf(format, args...)))
+}
+
+// Bold adds a bold text.
+func (b *Builder) Bold(parts ...interface{}) *Builder {
+	return b.Append(Bold(parts...))
+}
+
+// Italic adds an italic text.
+func (b *Builder) Italic(parts ...interface{}) *Builder {
+	return b.Append(Italic(parts...))
+}
+
+// Underline adds an underlined text.
+func (b *Builder) Underline(parts ...interface{}) *Builder {
+	return b.Append(Underline(parts...))
+}
+
+// Strikethrough adds a strikethrough text.
+func (b *Builder) Strikethrough(parts ...interface{}) *Builder {
+	return b.Append(Strikethrough(parts...))
+}
+
+// Spoiler adds a spoiler.
+func (b *Builder) Spoiler(parts ...interface{}) *Builder {
+	return b.Append(Spoiler(parts...))
+}
+
+// Blockquote adds a block quotation.
+func (b *Builder) Blockquote(parts ...interface{}) *Builder {
+	return b.Append(Blockquote(parts...))
+}
+
+// ExpandableBlockquote adds a collapsed block quotation.
+func (b *Builder) ExpandableBlockquote(parts ...interface{}) *Builder {
+	return b.Append(ExpandableBlockquote(parts...))
+}
+
+// Code adds an inline monowidth code.
+func (b *Builder) Code(s string) *Builder {
+	return b.Append(Code(s))
+}
+
+// Pre adds a pre-formatted code block.
+func (b *Builder) Pre(s, language string) *Builder {
+	return b.Append(Pre(s, language))
+}
+
+// Link adds a clickable text URL.
+func (b *Builder) Link(url string, parts ...interface{}) *Builder {
+	return b.Append(Link(url, parts...))
+}
+
+// Mention adds a mention of the user.
+func (b *Builder) Mention(user *tele.User, parts ...interface{}) *Builder {
+	return b.Append(Mention(user, parts...))
+}
+
+// CustomEmoji adds a custom emoji.
+func (b *Builder) CustomEmoji(emoji, id string) *Builder {
+	return b.Append(CustomEmoji(emoji, id))
+}
+
+// Nodes returns the nodes added to the builder.
+func (b *Builder) Nodes() []Node {
+	return b.nodes
+}
+
+// String returns the plain text without any formatting.
+func (b *Builder) String() string {
+	text, _ := b.Entities()
+	return text
+}
+
+// Entities returns the plain text and its entities
+// with the offsets in UTF-16 code units.
+func (b *Builder) Entities() (string, tele.Entities) {
+	var w entityWriter
+	w.write(b.nodes)
+	return w.text.String(), w.entities
+}
+
+// Render returns the text marked up and escaped for the parse mode.
+// ModeDefault renders the plain text.
+func (b *Builder) Render(mode tele.ParseMode) string {
+	return render(b.nodes, mode)
+}
+
+// Send implements tele.Sendable. It sends the text with
+// the entities, so the parse mode of the options is ignored.
+func (b *Builder) Send(bot *tele.Bot, to tele.Recipient, opt *tele.SendOptions) (*tele.Message, error) {
+	text, entities := b.Entities()
+
+	var o tele.SendOptions
+	if opt != nil {
+		o = *opt
+	}
+	o.ParseMode = tele.ModeDefault
+	o.Entities = entities
+
+	return bot.Send(to, text, &o)
+}
+
+type entityWriter struct {
+	text     strings.Builder
+	offset   int
+	entities tele.Entities
+}
+
+func (w *entityWriter) write(nodes []Node) {
+	for _, n := range nodes {
+		start, i := w.offset, len(w.entities)
+		if n.typ != "" {
+			// Reserve the place, so the outer entity goes first.
+			w.entities = append(w.entities, tele.MessageEntity{})
+		}
+
+		if n.children == nil {
+			w.text.WriteString(n.text)
+			w.offset += utf16Len(n.text)
+		} else {
+			w.write(n.children)
+		}
+
+		if n.typ == "" {
+			continue
+		}
+		if w.offset == start {
+			w.entities = w.entities[:i]
+			continue
+		}
+
+		w.entities[i] = tele.MessageEntity{
+			Type:          n.typ,
+			Offset:        start,
+			Length:        w.offset - start,
+			URL:           n.url,
+			User:          n.user,
+			Language:      n.language,
+			CustomEmojiID: n.emojiID,
+		}
+	}
+}
+
+// utf16Len returns the length of the string in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 && r <= unicode.MaxRune {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,66 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tele "gopkg.in/telebot.v4"
+)
+
+func TestBuilderEntities(t *testing.T) {
+	user := &tele.User{ID: 42, FirstName: "John"}
+
+	text, entities := New().
+		Text("👋 ").
+		Bold("Hi, ", Italic(Mention(user)), "!").
+		Text(" ").
+		Pre("fmt.Println()", "go").
+		Link("https://go.dev").
+		Bold("").
+		Entities()
+
+	assert.Equal(t, "👋 Hi, John! fmt.Println()https://go.dev", text)
+	assert.Equal(t, tele.Entities{
+		{Type: tele.EntityBold, Offset: 3, Length: 9},
+		{Type: tele.EntityItalic, Offset: 7, Length: 4},
+		{Type: tele.EntityTMention, Offset: 7, Length: 4, User: user},
+		{Type: tele.EntityCodeBlock, Offset: 13, Length: 13, Language: "go"},
+		{Type: tele.EntityTextLink, Offset: 26, Length: 14, URL: "https://go.dev"},
+	}, entities)
+}
+
+func TestBuilderRender(t *testing.T) {
+	b := New().
+		Bold("1+1=", Italic("2")).
+		Text(" <a_b> ").
+		Code("x`y").
+		Link("https://example.com/(1)", "link").
+		Italic(Underline("iu")).
+		CustomEmoji("👍", "123")
+
+	assert.Equal(t,
+		`<b>1+1=<i>2</i></b> &lt;a_b&gt; <code>x`+"`"+`y</code><a href="https://example.com/(1)">link</a><i><u>iu</u></i><tg-emoji emoji-id="123">👍</tg-emoji>`,
+		b.Render(tele.ModeHTML))
+
+	assert.Equal(t,
+		"*1\\+1\\=_2_* <a\\_b\\> `x\\`y`[link](https://example.com/(1\\))_\r__iu__\r_![👍](tg://emoji?id=123)",
+		b.Render(tele.ModeMarkdownV2))
+
+	assert.Equal(t,
+		"*1+1=2* <a\\_b> `x`\\``y`[link](https://example.com/(1))_iu_👍",
+		b.Render(tele.ModeMarkdown))
+
+	assert.Equal(t, "1+1=2 <a_b> x`ylinkiu👍", b.Render(tele.ModeDefault))
+	assert.Equal(t, "1+1=2 <a_b> x`ylinkiu👍", b.String())
+
+	assert.Equal(t,
+		">line\n>quote\n**>hidden||",
+		New(Blockquote("line\nquote\n"), ExpandableBlockquote("hidden")).Render(tele.ModeMarkdownV2))
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, "&lt;b&gt;&amp;&quot;", Escape(`<b>&"`, tele.ModeHTML))
+	assert.Equal(t, `\*a\_b\* \[c\]\(d\) 1\.5\!`, Escape("*a_b* [c](d) 1.5!", tele.ModeMarkdownV2))
+	assert.Equal(t, `\*a\_b\* \[c](d)`, Escape("*a_b* [c](d)", tele.ModeMarkdown))
+	assert.Equal(t, "*a*", Escape("*a*", tele.ModeDefault))
+}
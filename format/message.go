@@ -0,0 +1,114 @@
+package format
+
+import (
+	"sort"
+	"unicode/utf16"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// FromMessage returns the builder with the message's text and entities,
+// or the caption and its entities if the message has no text. It's useful
+// to re-send the received message in a different parse mode:
+//
+//	html := format.FromMessage(c.Message()).Render(tele.ModeHTML)
+func FromMessage(m *tele.Message) *Builder {
+	if m.Text != "" {
+		return FromEntities(m.Text, m.Entities)
+	}
+	return FromEntities(m.Caption, m.CaptionEntities)
+}
+
+// FromEntities returns the builder with the text formatted by the entities,
+// whose offsets are in UTF-16 code units. Overlapping entities are split
+// into the properly nested ones; invalid entities are skipped.
+func FromEntities(text string, entities tele.Entities) *Builder {
+	u := utf16.Encode([]rune(text))
+
+	valid := make([]tele.MessageEntity, 0, len(entities))
+	for _, e := range entities {
+		if e.Offset >= 0 && e.Length > 0 && e.Offset+e.Length <= len(u) {
+			valid = append(valid, e)
+		}
+	}
+
+	return &Builder{nodes: entityNodes(u, 0, len(u), valid)}
+}
+
+// entityNodes builds the nodes of the u[start:end] text
+// formatted by the entities inside of it.
+func entityNodes(u []uint16, start, end int, entities []tele.MessageEntity) []Node {
+	var nodes []Node
+	pos := start
+
+	for len(entities) > 0 {
+		sortEntities(entities)
+		e := entities[0]
+		eEnd := e.Offset + e.Length
+
+		if e.Offset > pos {
+			nodes = append(nodes, Text(decode(u[pos:e.Offset])))
+		}
+
+		// The entities crossing the end of e are split in two.
+		var inner, rest []tele.MessageEntity
+		for _, o := range entities[1:] {
+			oEnd := o.Offset + o.Length
+			switch {
+			case o.Offset >= eEnd:
+				rest = append(rest, o)
+			case oEnd <= eEnd:
+				inner = append(inner, o)
+			default:
+				in, out := o, o
+				in.Length = eEnd - o.Offset
+				out.Offset, out.Length = eEnd, oEnd-eEnd
+				inner = append(inner, in)
+				rest = append(rest, out)
+			}
+		}
+
+		nodes = append(nodes, entityNode(u, e, inner))
+		entities = rest
+		pos = eEnd
+	}
+
+	if pos < end {
+		nodes = append(nodes, Text(decode(u[pos:end])))
+	}
+	return nodes
+}
+
+func entityNode(u []uint16, e tele.MessageEntity, inner []tele.MessageEntity) Node {
+	n := Node{
+		typ:      e.Type,
+		url:      e.URL,
+		user:     e.User,
+		language: e.Language,
+		emojiID:  e.CustomEmojiID,
+	}
+
+	end := e.Offset + e.Length
+	switch e.Type {
+	case tele.EntityCode, tele.EntityCodeBlock, tele.EntityCustomEmoji:
+		n.text = decode(u[e.Offset:end])
+	default:
+		n.children = entityNodes(u, e.Offset, end, inner)
+	}
+	return n
+}
+
+// sortEntities orders the entities by the offset,
+// putting the outer ones first.
+func sortEntities(entities []tele.MessageEntity) {
+	sort.SliceStable(entities, func(i, j int) bool {
+		if entities[i].Offset != entities[j].Offset {
+			return entities[i].Offset < entities[j].Offset
+		}
+		return entities[i].Length > entities[j].Length
+	})
+}
+
+func decode(u []uint16) string {
+	return string(utf16.Decode(u))
+}
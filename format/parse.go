@@ -0,0 +1,460 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// ErrUnsupportedMode is returned by Parse for the parse modes
+// other than ModeHTML, ModeMarkdownV2 and ModeDefault.
+var ErrUnsupportedMode = errors.New("format: unsupported parse mode")
+
+// Parse parses the marked up text into the builder, so it can be converted
+// to the plain text with entities by Builder.Entities. ModeDefault returns
+// the text as is.
+func Parse(s string, mode tele.ParseMode) (*Builder, error) {
+	switch mode {
+	case tele.ModeHTML:
+		return ParseHTML(s)
+	case tele.ModeMarkdownV2:
+		return ParseMarkdownV2(s)
+	case tele.ModeDefault:
+		return New(s), nil
+	}
+	return nil, ErrUnsupportedMode
+}
+
+// parser keeps the stack of the entities being parsed.
+type parser struct {
+	stack []frame
+}
+
+type frame struct {
+	node Node
+	tag  string
+}
+
+func newParser() *parser {
+	return &parser{stack: []frame{{node: Node{children: []Node{}}}}}
+}
+
+func (p *parser) top() *frame {
+	return &p.stack[len(p.stack)-1]
+}
+
+func (p *parser) text(s string) {
+	if s == "" {
+		return
+	}
+
+	top := p.top()
+	if last := len(top.node.children) - 1; last >= 0 {
+		if n := &top.node.children[last]; n.typ == "" && n.children == nil {
+			n.text += s
+			return
+		}
+	}
+	top.node.children = append(top.node.children, Text(s))
+}
+
+func (p *parser) open(n Node, tag string) {
+	n.children = []Node{}
+	p.stack = append(p.stack, frame{node: n, tag: tag})
+}
+
+// find returns the index of the innermost open frame with the tag.
+func (p *parser) find(tag string) int {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].tag == tag {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *parser) close() {
+	n := p.top().node
+	p.stack = p.stack[:len(p.stack)-1]
+
+	switch n.typ {
+	case "":
+		for _, c := range n.children {
+			p.append(c)
+		}
+		return
+	case tele.EntityCode, tele.EntityCodeBlock, tele.EntityCustomEmoji:
+		n.text, n.children = n.plain(), nil
+	}
+	p.append(n)
+}
+
+func (p *parser) append(n Node) {
+	if n.typ == "" && n.children == nil {
+		p.text(n.text)
+		return
+	}
+	top := p.top()
+	top.node.children = append(top.node.children, n)
+}
+
+func (p *parser) builder() *Builder {
+	return &Builder{nodes: p.stack[0].node.children}
+}
+
+// ParseHTML parses the text marked up as described in the ModeHTML section
+// of the Bot API docs.
+func ParseHTML(s string) (*Builder, error) {
+	p := newParser()
+
+	for s != "" {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			p.text(html.UnescapeString(s))
+			break
+		}
+		p.text(html.UnescapeString(s[:i]))
+		s = s[i:]
+
+		j := strings.IndexByte(s, '>')
+		if j < 0 {
+			return nil, errors.New("format: unclosed tag")
+		}
+		tag := s[1:j]
+		s = s[j+1:]
+
+		if strings.HasPrefix(tag, "/") {
+			name := strings.ToLower(strings.TrimSpace(tag[1:]))
+			i := p.find(htmlAlias(name))
+			if i < 0 || i != len(p.stack)-1 {
+				return nil, fmt.Errorf("format: unexpected end tag </%s>", name)
+			}
+			p.close()
+			continue
+		}
+
+		name, attrs := parseTag(tag)
+		n, err := htmlNode(name, attrs)
+		if err != nil {
+			return nil, err
+		}
+
+		// <pre><code class="language-go"> sets the language of the block.
+		if name == "code" && p.top().tag == "pre" && len(p.top().node.children) == 0 {
+			p.top().node.language = strings.TrimPrefix(attrs["class"], "language-")
+			n.typ = ""
+		}
+		p.open(n, htmlAlias(name))
+	}
+
+	if len(p.stack) > 1 {
+		return nil, fmt.Errorf("format: unclosed tag <%s>", p.top().tag)
+	}
+	return p.builder(), nil
+}
+
+// htmlAlias returns the canonical name of the tag.
+func htmlAlias(name string) string {
+	switch name {
+	case "strong":
+		return "b"
+	case "em":
+		return "i"
+	case "ins":
+		return "u"
+	case "strike", "del":
+		return "s"
+	}
+	return name
+}
+
+func htmlNode(name string, attrs map[string]string) (Node, error) {
+	switch htmlAlias(name) {
+	case "b":
+		return Node{typ: tele.EntityBold}, nil
+	case "i":
+		return Node{typ: tele.EntityItalic}, nil
+	case "u":
+		return Node{typ: tele.EntityUnderline}, nil
+	case "s":
+		return Node{typ: tele.EntityStrikethrough}, nil
+	case "tg-spoiler":
+		return Node{typ: tele.EntitySpoiler}, nil
+	case "span":
+		if attrs["class"] == "tg-spoiler" {
+			return Node{typ: tele.EntitySpoiler}, nil
+		}
+	case "code":
+		return Node{typ: tele.EntityCode}, nil
+	case "pre":
+		return Node{typ: tele.EntityCodeBlock}, nil
+	case "blockquote":
+		if _, ok := attrs["expandable"]; ok {
+			return Node{typ: tele.EntityEBlockquote}, nil
+		}
+		return Node{typ: tele.EntityBlockquote}, nil
+	case "tg-emoji":
+		return Node{typ: tele.EntityCustomEmoji, emojiID: attrs["emoji-id"]}, nil
+	case "a":
+		return linkNode(attrs["href"]), nil
+	}
+	return Node{}, fmt.Errorf("format: unsupported tag <%s>", name)
+}
+
+// linkNode returns a mention node for tg://user links
+// and a text link node for the others.
+func linkNode(url string) Node {
+	if s := strings.TrimPrefix(url, "tg://user?id="); s != url {
+		if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return Node{typ: tele.EntityTMention, user: &tele.User{ID: id}}
+		}
+	}
+	return Node{typ: tele.EntityTextLink, url: url}
+}
+
+// parseTag splits the opening tag into the lowercase name and attributes.
+func parseTag(tag string) (string, map[string]string) {
+	tag = strings.TrimSpace(tag)
+	i := strings.IndexAny(tag, " \t\n")
+	if i < 0 {
+		return strings.ToLower(tag), nil
+	}
+
+	name, s := strings.ToLower(tag[:i]), tag[i:]
+	attrs := make(map[string]string)
+
+	for {
+		s = strings.TrimLeft(s, " \t\n")
+		if s == "" {
+			return name, attrs
+		}
+
+		i := strings.IndexAny(s, "= \t\n")
+		if i < 0 || s[i] != '=' {
+			if i < 0 {
+				i = len(s)
+			}
+			attrs[strings.ToLower(s[:i])] = ""
+			s = s[i:]
+			continue
+		}
+
+		key, s2 := strings.ToLower(s[:i]), s[i+1:]
+
+		var value string
+		if s2 != "" && (s2[0] == '"' || s2[0] == '\'') {
+			j := strings.IndexByte(s2[1:], s2[0])
+			if j < 0 {
+				j = len(s2) - 1
+			}
+			value, s = s2[1:j+1], s2[min(j+2, len(s2)):]
+		} else {
+			j := strings.IndexAny(s2, " \t\n")
+			if j < 0 {
+				j = len(s2)
+			}
+			value, s = s2[:j], s2[j:]
+		}
+		attrs[key] = html.UnescapeString(value)
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// ParseMarkdownV2 parses the text marked up as described in the
+// ModeMarkdownV2 section of the Bot API docs. Unlike Telegram, it doesn't
+// fail on the unescaped reserved characters, which have no meaning
+// in the place they're used.
+func ParseMarkdownV2(s string) (*Builder, error) {
+	p := newParser()
+	lineStart := true
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		atLineStart := lineStart
+		lineStart = false
+
+		switch {
+		case c == '\\' && i+1 < len(s):
+			p.text(s[i+1 : i+2])
+			i += 2
+
+		case atLineStart && strings.HasPrefix(s[i:], "**>"):
+			p.open(Node{typ: tele.EntityEBlockquote}, "**>")
+			i += 3
+
+		case atLineStart && c == '>':
+			if p.quote() < 0 {
+				p.open(Node{typ: tele.EntityBlockquote}, ">")
+			}
+			i++
+
+		case c == '\n':
+			// The quotation lasts while the lines start with >.
+			if q := p.quote(); q > 0 && !strings.HasPrefix(s[i+1:], ">") {
+				if err := p.closeTo(q); err != nil {
+					return nil, err
+				}
+			}
+			p.text("\n")
+			lineStart = true
+			i++
+
+		case c == '\r' && i > 0 && s[i-1] == '_' && strings.HasPrefix(s[i+1:], "_"):
+			// \r separates the italic and underline markers.
+			i++
+
+		case strings.HasPrefix(s[i:], "```"):
+			end := strings.Index(s[i+3:], "```")
+			if end < 0 {
+				return nil, errors.New("format: unclosed pre entity")
+			}
+			code := s[i+3 : i+3+end]
+			n := Pre(code, "")
+			if nl := strings.IndexByte(code, '\n'); nl >= 0 {
+				n.language, n.text = code[:nl], code[nl+1:]
+			}
+			n.text = unescapeCode(n.text)
+			p.append(n)
+			i += 6 + end
+
+		case c == '`':
+			end := indexUnescaped(s[i+1:], '`')
+			if end < 0 {
+				return nil, errors.New("format: unclosed code entity")
+			}
+			p.append(Code(unescapeCode(s[i+1 : i+1+end])))
+			i += 2 + end
+
+		case strings.HasPrefix(s[i:], "||"):
+			if q := p.find("**>"); q == len(p.stack)-1 && (i+2 == len(s) || s[i+2] == '\n') {
+				p.close()
+			} else if err := p.toggle(tele.EntitySpoiler, "||"); err != nil {
+				return nil, err
+			}
+			i += 2
+
+		case strings.HasPrefix(s[i:], "__"):
+			if err := p.toggle(tele.EntityUnderline, "__"); err != nil {
+				return nil, err
+			}
+			i += 2
+
+		case c == '_' || c == '*' || c == '~':
+			typ := map[byte]tele.EntityType{
+				'_': tele.EntityItalic,
+				'*': tele.EntityBold,
+				'~': tele.EntityStrikethrough,
+			}[c]
+			if err := p.toggle(typ, string(c)); err != nil {
+				return nil, err
+			}
+			i++
+
+		case strings.HasPrefix(s[i:], "!["):
+			p.open(Node{typ: tele.EntityCustomEmoji}, "[")
+			i += 2
+
+		case c == '[':
+			p.open(Node{typ: tele.EntityTextLink}, "[")
+			i++
+
+		case c == ']' && p.top().tag == "[" && strings.HasPrefix(s[i+1:], "("):
+			end := indexUnescaped(s[i+2:], ')')
+			if end < 0 {
+				return nil, errors.New("format: unclosed link url")
+			}
+			url := unescapeCode(s[i+2 : i+2+end])
+
+			top := p.top()
+			if top.node.typ == tele.EntityCustomEmoji {
+				top.node.emojiID = strings.TrimPrefix(url, "tg://emoji?id=")
+			} else {
+				children := top.node.children
+				top.node = linkNode(url)
+				top.node.children = children
+			}
+			p.close()
+			i += 3 + end
+
+		default:
+			p.text(s[i : i+1])
+			i++
+		}
+	}
+
+	if q := p.quote(); q > 0 {
+		if err := p.closeTo(q); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.stack) > 1 {
+		return nil, fmt.Errorf("format: unclosed entity %s", p.top().tag)
+	}
+	return p.builder(), nil
+}
+
+// toggle closes the entity if it's the innermost one, or opens it otherwise.
+func (p *parser) toggle(typ tele.EntityType, tag string) error {
+	i := p.find(tag)
+	switch {
+	case i < 0:
+		p.open(Node{typ: typ}, tag)
+	case i == len(p.stack)-1:
+		p.close()
+	default:
+		return fmt.Errorf("format: entity %s is not properly nested", tag)
+	}
+	return nil
+}
+
+// quote returns the index of the open quotation frame, or -1.
+func (p *parser) quote() int {
+	if i := p.find(">"); i > 0 {
+		return i
+	}
+	return p.find("**>")
+}
+
+// closeTo closes the frames up to the i-th one, which must be innermost.
+func (p *parser) closeTo(i int) error {
+	if i != len(p.stack)-1 {
+		return fmt.Errorf("format: entity %s is not closed in the quotation", p.top().tag)
+	}
+	p.close()
+	return nil
+}
+
+// indexUnescaped returns the index of the first c not escaped by a backslash.
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeCode removes the backslashes escaping the characters.
+func unescapeCode(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
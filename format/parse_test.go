@@ -0,0 +1,118 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v4"
+)
+
+func TestFromMessage(t *testing.T) {
+	// "bold" and "italic" overlap: **bo__ld** it__alic
+	m := &tele.Message{
+		Text: "🔥 bold italic <tag>",
+		Entities: tele.Entities{
+			{Type: tele.EntityBold, Offset: 3, Length: 4},
+			{Type: tele.EntityItalic, Offset: 5, Length: 9},
+			{Type: tele.EntityCode, Offset: 15, Length: 5},
+			{Type: tele.EntityBold, Offset: 100, Length: 1},
+		},
+	}
+
+	b := FromMessage(m)
+	assert.Equal(t, "🔥 <b>bo<i>ld</i></b><i> italic</i> <code>&lt;tag&gt;</code>", b.Render(tele.ModeHTML))
+	assert.Equal(t, "🔥 *bo_ld_*_ italic_ `<tag>`", b.Render(tele.ModeMarkdownV2))
+
+	text, entities := b.Entities()
+	assert.Equal(t, m.Text, text)
+	assert.Equal(t, tele.Entities{
+		{Type: tele.EntityBold, Offset: 3, Length: 4},
+		{Type: tele.EntityItalic, Offset: 5, Length: 2},
+		{Type: tele.EntityItalic, Offset: 7, Length: 7},
+		{Type: tele.EntityCode, Offset: 15, Length: 5},
+	}, entities)
+
+	m = &tele.Message{
+		Caption:         "link",
+		CaptionEntities: tele.Entities{{Type: tele.EntityTextLink, Offset: 0, Length: 4, URL: "https://go.dev"}},
+	}
+	assert.Equal(t, `<a href="https://go.dev">link</a>`, FromMessage(m).Render(tele.ModeHTML))
+}
+
+func TestParseHTML(t *testing.T) {
+	b, err := ParseHTML(`<b>bold <i>both</i></b> &lt;&#33;&gt; <a href="tg://user?id=42">John</a> ` +
+		`<pre><code class="language-go">x := 1</code></pre><span class="tg-spoiler">s</span>` +
+		`<blockquote expandable>q</blockquote><tg-emoji emoji-id="5">👍</tg-emoji>`)
+	require.NoError(t, err)
+
+	text, entities := b.Entities()
+	assert.Equal(t, "bold both <!> John x := 1sq👍", text)
+	assert.Equal(t, tele.Entities{
+		{Type: tele.EntityBold, Offset: 0, Length: 9},
+		{Type: tele.EntityItalic, Offset: 5, Length: 4},
+		{Type: tele.EntityTMention, Offset: 14, Length: 4, User: &tele.User{ID: 42}},
+		{Type: tele.EntityCodeBlock, Offset: 19, Length: 6, Language: "go"},
+		{Type: tele.EntitySpoiler, Offset: 25, Length: 1},
+		{Type: tele.EntityEBlockquote, Offset: 26, Length: 1},
+		{Type: tele.EntityCustomEmoji, Offset: 27, Length: 2, CustomEmojiID: "5"},
+	}, entities)
+
+	_, err = ParseHTML("<b>unclosed")
+	assert.Error(t, err)
+	_, err = ParseHTML("<b><i>bad</b></i>")
+	assert.Error(t, err)
+	_, err = ParseHTML("<br>")
+	assert.Error(t, err)
+}
+
+func TestParseMarkdownV2(t *testing.T) {
+	b, err := ParseMarkdownV2("*bold _both_* \\*not\\* [link](https://go.dev/\\)) `a\\`b`\n" +
+		"```go\nx := 1```||s||\n>q1\n>q2\nend")
+	require.NoError(t, err)
+
+	text, entities := b.Entities()
+	assert.Equal(t, "bold both *not* link a`b\nx := 1s\nq1\nq2\nend", text)
+	assert.Equal(t, tele.Entities{
+		{Type: tele.EntityBold, Offset: 0, Length: 9},
+		{Type: tele.EntityItalic, Offset: 5, Length: 4},
+		{Type: tele.EntityTextLink, Offset: 16, Length: 4, URL: "https://go.dev/)"},
+		{Type: tele.EntityCode, Offset: 21, Length: 3},
+		{Type: tele.EntityCodeBlock, Offset: 25, Length: 6, Language: "go"},
+		{Type: tele.EntitySpoiler, Offset: 31, Length: 1},
+		{Type: tele.EntityBlockquote, Offset: 33, Length: 5},
+	}, entities)
+
+	_, err = ParseMarkdownV2("*unclosed")
+	assert.Error(t, err)
+	_, err = ParseMarkdownV2("*a _b* c_")
+	assert.Error(t, err)
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	user := &tele.User{ID: 42, FirstName: "John"}
+	b := New().
+		Bold("1+1=", Italic("2")).
+		Text(" <a_b> ").
+		Code("x`y").
+		Link("https://example.com/(1)", "link").
+		Italic(Underline("iu")).
+		Mention(user).
+		CustomEmoji("👍", "123").
+		Text("\n").
+		Blockquote("line\nquote").
+		Text("\n").
+		ExpandableBlockquote("hidden ", Spoiler("s"))
+
+	text, entities := b.Entities()
+	entities[6].User = &tele.User{ID: 42}
+
+	for _, mode := range []tele.ParseMode{tele.ModeHTML, tele.ModeMarkdownV2} {
+		parsed, err := Parse(b.Render(mode), mode)
+		require.NoError(t, err, mode)
+
+		parsedText, parsedEntities := parsed.Entities()
+		assert.Equal(t, text, parsedText, mode)
+		assert.Equal(t, entities, parsedEntities, mode)
+	}
+}
@@ -0,0 +1,261 @@
+package format
+
+import (
+	"strconv"
+	"strings"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+var (
+	htmlEscaper = strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		`"`, "&quot;",
+	)
+	markdownEscaper = strings.NewReplacer(
+		"_", `\_`,
+		"*", `\*`,
+		"`", "\\`",
+		"[", `\[`,
+	)
+	markdownV2Escaper = strings.NewReplacer(
+		`\`, `\\`,
+		"_", `\_`,
+		"*", `\*`,
+		"[", `\[`,
+		"]", `\]`,
+		"(", `\(`,
+		")", `\)`,
+		"~", `\~`,
+		"`", "\\`",
+		">", `\>`,
+		"#", `\#`,
+		"+", `\+`,
+		"-", `\-`,
+		"=", `\=`,
+		"|", `\|`,
+		"{", `\{`,
+		"}", `\}`,
+		".", `\.`,
+		"!", `\!`,
+	)
+	markdownV2CodeEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		"`", "\\`",
+	)
+	markdownV2LinkEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		")", `\)`,
+	)
+)
+
+// EscapeHTML escapes the untrusted text for ModeHTML.
+func EscapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// EscapeMarkdown escapes the untrusted text for ModeMarkdown.
+// It's only valid outside of entities.
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// EscapeMarkdownV2 escapes the untrusted text for ModeMarkdownV2.
+func EscapeMarkdownV2(s string) string {
+	return markdownV2Escaper.Replace(s)
+}
+
+// Escape escapes the untrusted text for the parse mode.
+// ModeDefault returns the text as is.
+func Escape(s string, mode tele.ParseMode) string {
+	switch mode {
+	case tele.ModeHTML:
+		return EscapeHTML(s)
+	case tele.ModeMarkdown:
+		return EscapeMarkdown(s)
+	case tele.ModeMarkdownV2:
+		return EscapeMarkdownV2(s)
+	}
+	return s
+}
+
+func render(nodes []Node, mode tele.ParseMode) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch mode {
+		case tele.ModeHTML:
+			sb.WriteString(renderHTML(n))
+		case tele.ModeMarkdown:
+			sb.WriteString(renderMarkdown(n))
+		case tele.ModeMarkdownV2:
+			appendMarkdownV2(&sb, renderMarkdownV2(n))
+		default:
+			sb.WriteString(n.plain())
+		}
+	}
+	return sb.String()
+}
+
+// plain returns the text of the node without formatting.
+func (n Node) plain() string {
+	if n.children == nil {
+		return n.text
+	}
+	var sb strings.Builder
+	for _, c := range n.children {
+		sb.WriteString(c.plain())
+	}
+	return sb.String()
+}
+
+func userURL(user *tele.User) string {
+	if user == nil {
+		return ""
+	}
+	return "tg://user?id=" + strconv.FormatInt(user.ID, 10)
+}
+
+func renderHTML(n Node) string {
+	switch n.typ {
+	case tele.EntityCode:
+		return "<code>" + EscapeHTML(n.text) + "</code>"
+	case tele.EntityCodeBlock:
+		if n.language == "" {
+			return "<pre>" + EscapeHTML(n.text) + "</pre>"
+		}
+		return `<pre><code class="language-` + EscapeHTML(n.language) + `">` +
+			EscapeHTML(n.text) + "</code></pre>"
+	case tele.EntityCustomEmoji:
+		return `<tg-emoji emoji-id="` + EscapeHTML(n.emojiID) + `">` + EscapeHTML(n.text) + "</tg-emoji>"
+	}
+
+	inner := EscapeHTML(n.text)
+	if n.children != nil {
+		inner = render(n.children, tele.ModeHTML)
+	}
+
+	switch n.typ {
+	case tele.EntityBold:
+		return "<b>" + inner + "</b>"
+	case tele.EntityItalic:
+		return "<i>" + inner + "</i>"
+	case tele.EntityUnderline:
+		return "<u>" + inner + "</u>"
+	case tele.EntityStrikethrough:
+		return "<s>" + inner + "</s>"
+	case tele.EntitySpoiler:
+		return "<tg-spoiler>" + inner + "</tg-spoiler>"
+	case tele.EntityBlockquote:
+		return "<blockquote>" + inner + "</blockquote>"
+	case tele.EntityEBlockquote:
+		return "<blockquote expandable>" + inner + "</blockquote>"
+	case tele.EntityTextLink:
+		return `<a href="` + EscapeHTML(n.url) + `">` + inner + "</a>"
+	case tele.EntityTMention:
+		return `<a href="` + userURL(n.user) + `">` + inner + "</a>"
+	}
+	return inner
+}
+
+func renderMarkdownV2(n Node) string {
+	switch n.typ {
+	case tele.EntityCode:
+		return "`" + markdownV2CodeEscaper.Replace(n.text) + "`"
+	case tele.EntityCodeBlock:
+		return "```" + n.language + "\n" + markdownV2CodeEscaper.Replace(n.text) + "```"
+	case tele.EntityCustomEmoji:
+		return "![" + EscapeMarkdownV2(n.text) + "](tg://emoji?id=" + n.emojiID + ")"
+	}
+
+	inner := EscapeMarkdownV2(n.text)
+	if n.children != nil {
+		inner = render(n.children, tele.ModeMarkdownV2)
+	}
+
+	wrap := func(marker string) string {
+		var sb strings.Builder
+		sb.WriteString(marker)
+		appendMarkdownV2(&sb, inner)
+		appendMarkdownV2(&sb, marker)
+		return sb.String()
+	}
+
+	switch n.typ {
+	case tele.EntityBold:
+		return wrap("*")
+	case tele.EntityItalic:
+		return wrap("_")
+	case tele.EntityUnderline:
+		return wrap("__")
+	case tele.EntityStrikethrough:
+		return wrap("~")
+	case tele.EntitySpoiler:
+		return wrap("||")
+	case tele.EntityBlockquote:
+		return quote(">", inner, "")
+	case tele.EntityEBlockquote:
+		return quote("**>", inner, "||")
+	case tele.EntityTextLink:
+		return "[" + inner + "](" + markdownV2LinkEscaper.Replace(n.url) + ")"
+	case tele.EntityTMention:
+		return "[" + inner + "](" + userURL(n.user) + ")"
+	}
+	return inner
+}
+
+// quote prefixes every line of the text, keeping
+// the trailing line feed out of the quotation.
+func quote(prefix, text, suffix string) string {
+	body := strings.TrimSuffix(text, "\n")
+	return prefix + strings.ReplaceAll(body, "\n", "\n>") + suffix + text[len(body):]
+}
+
+// appendMarkdownV2 separates the adjacent italic and underline markers
+// with \r, which is ignored by Telegram, to avoid the ambiguity of ___.
+func appendMarkdownV2(sb *strings.Builder, s string) {
+	cur := sb.String()
+	if strings.HasPrefix(s, "_") && strings.HasSuffix(cur, "_") && !escaped(cur, len(cur)-1) {
+		sb.WriteByte('\r')
+	}
+	sb.WriteString(s)
+}
+
+// escaped reports whether the i-th byte of s is escaped with a backslash.
+func escaped(s string, i int) bool {
+	n := 0
+	for i--; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func renderMarkdown(n Node) string {
+	// Legacy Markdown doesn't support nested entities,
+	// so the inner formatting is dropped.
+	text := n.plain()
+
+	switch n.typ {
+	case tele.EntityBold:
+		return markdownEntity("*", text)
+	case tele.EntityItalic:
+		return markdownEntity("_", text)
+	case tele.EntityCode:
+		return markdownEntity("`", text)
+	case tele.EntityCodeBlock:
+		return "```" + n.language + "\n" + text + "```"
+	case tele.EntityTextLink:
+		return "[" + text + "](" + n.url + ")"
+	case tele.EntityTMention:
+		return "[" + text + "](" + userURL(n.user) + ")"
+	}
+	return EscapeMarkdown(text)
+}
+
+// markdownEntity wraps the text into the marker. Escaping isn't allowed
+// inside of entities, so the entity is closed and reopened around the
+// marker character, e.g. *2*\**2=4* for a bold 2*2=4.
+func markdownEntity(marker, text string) string {
+	return marker + strings.ReplaceAll(text, marker, marker+`\`+marker+marker) + marker
+}
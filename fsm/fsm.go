@@ -0,0 +1,351 @@
+// Package fsm implements multi-step conversations as finite-state machines.
+//
+// Example:
+//
+//	m := fsm.New(b, fsm.NewMemoryStorage())
+//	m.CancelCommand = "/cancel"
+//
+//	b.Handle("/signup", func(c tele.Context) error {
+//		if err := fsm.Get(c).Set("await_email"); err != nil {
+//			return err
+//		}
+//		return c.Send("What's your email?")
+//	}, m.Middleware())
+//
+//	m.Handle(tele.OnText, "await_email", func(c tele.Context) error {
+//		conv := fsm.Get(c)
+//		if err := conv.Put("email", c.Text()); err != nil {
+//			return err
+//		}
+//		return conv.Finish()
+//	})
+package fsm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// State is a named step of the conversation.
+type State string
+
+const (
+	// Default is the state of the user out of any conversation.
+	Default State = ""
+
+	// Any matches every state, except Default, when passed to the Handle.
+	Any State = "*"
+)
+
+// ErrNoConversation is returned when the context has no conversation
+// loaded, which means the machine middleware hasn't been applied.
+var ErrNoConversation = errors.New("fsm: conversation is not loaded")
+
+// Router is implemented by both tele.Bot and tele.Group.
+type Router interface {
+	Handle(endpoint interface{}, h tele.HandlerFunc, m ...tele.MiddlewareFunc)
+}
+
+// Key identifies the conversation in the storage.
+type Key struct {
+	ChatID int64
+	UserID int64
+}
+
+// String returns the key in the "<chat>:<user>" form.
+func (k Key) String() string {
+	return strconv.FormatInt(k.ChatID, 10) + ":" + strconv.FormatInt(k.UserID, 10)
+}
+
+func parseKey(s string) (Key, error) {
+	var k Key
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return k, fmt.Errorf("fsm: bad key %q", s)
+	}
+
+	var err error
+	if k.ChatID, err = strconv.ParseInt(s[:i], 10, 64); err != nil {
+		return k, err
+	}
+	if k.UserID, err = strconv.ParseInt(s[i+1:], 10, 64); err != nil {
+		return k, err
+	}
+	return k, nil
+}
+
+// KeyFunc extracts the conversation key from the context.
+type KeyFunc func(tele.Context) Key
+
+// ChatSenderKey is the default KeyFunc, which runs separate
+// conversations with each user in every chat.
+func ChatSenderKey(c tele.Context) Key {
+	var k Key
+	if chat := c.Chat(); chat != nil {
+		k.ChatID = chat.ID
+	}
+	if user := c.Sender(); user != nil {
+		k.UserID = user.ID
+	}
+	return k
+}
+
+// Machine routes updates to the handlers of the current state.
+type Machine struct {
+	// Key extracts the conversation key, defaulted to ChatSenderKey.
+	Key KeyFunc
+
+	// Timeout resets the conversation to the Default state if the user
+	// hasn't moved it forward in time. Zero means no timeout.
+	Timeout time.Duration
+
+	// OnTimeout is called, if set, once the expired conversation is reset.
+	// The update is passed further to the Default state handlers after.
+	OnTimeout tele.HandlerFunc
+
+	// CancelCommand resets the conversation from any state, e.g. "/cancel".
+	CancelCommand string
+
+	// OnCancel is called, if set, when the conversation is cancelled.
+	OnCancel tele.HandlerFunc
+
+	r        Router
+	storage  Storage
+	mu       sync.Mutex
+	handlers map[string]map[State]tele.HandlerFunc
+}
+
+// New returns a new machine, which registers its handlers
+// on the given router and keeps the conversations in the storage.
+func New(r Router, storage Storage) *Machine {
+	return &Machine{
+		Key:      ChatSenderKey,
+		r:        r,
+		storage:  storage,
+		handlers: make(map[string]map[State]tele.HandlerFunc),
+	}
+}
+
+// Handle sets the handler for the endpoint in the given state. Different
+// states may have their own handlers of the same endpoint. The Any state
+// handler is used if there is no handler for the current state.
+// The middleware is applied to the handler of the given state only.
+func (m *Machine) Handle(endpoint interface{}, state State, h tele.HandlerFunc, mw ...tele.MiddlewareFunc) {
+	end := extractEndpoint(endpoint)
+	if end == "" {
+		panic("fsm: unsupported endpoint")
+	}
+
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	states, ok := m.handlers[end]
+	if !ok {
+		states = make(map[State]tele.HandlerFunc)
+		m.handlers[end] = states
+		m.r.Handle(endpoint, m.dispatch(end), m.Middleware())
+	}
+	states[state] = h
+}
+
+func (m *Machine) dispatch(end string) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		state := Get(c).State()
+
+		m.mu.Lock()
+		h, ok := m.handlers[end][state]
+		if !ok && state != Default {
+			h, ok = m.handlers[end][Any]
+		}
+		m.mu.Unlock()
+
+		if !ok {
+			return tele.Continue
+		}
+		return h(c)
+	}
+}
+
+// Middleware loads the conversation of the update, so it can be
+// accessed by Get. It also handles the cancel command and timeouts.
+// Loading is skipped if the conversation has been loaded already.
+func (m *Machine) Middleware() tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			if Get(c) != nil {
+				return next(c)
+			}
+
+			key := m.Key(c)
+			rec, err := m.storage.Get(key)
+			if err != nil {
+				return err
+			}
+			if rec == nil {
+				rec = &Record{}
+			}
+
+			conv := &Conversation{m: m, key: key, rec: rec}
+			c.Set(contextKey, conv)
+
+			if rec.State != Default && m.Timeout > 0 && time.Since(rec.Updated) > m.Timeout {
+				if err := conv.Finish(); err != nil {
+					return err
+				}
+				if m.OnTimeout != nil {
+					if err := m.OnTimeout(c); err != nil {
+						return err
+					}
+				}
+			}
+
+			if m.CancelCommand != "" && conv.State() != Default {
+				if msg := c.Message(); msg != nil && msg.Text == m.CancelCommand {
+					if err := conv.Finish(); err != nil {
+						return err
+					}
+					if m.OnCancel != nil {
+						return m.OnCancel(c)
+					}
+					return nil
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// In returns a middleware, which passes the update to the handler only
+// if the conversation is in one of the given states, otherwise the update
+// is passed to the next endpoint with tele.Continue. It requires the
+// machine middleware to be applied before, e.g. with Bot.Use.
+//
+//	b.Use(m.Middleware())
+//	b.Handle(tele.OnText, onEmail, fsm.In("await_email"))
+func In(states ...State) tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			conv := Get(c)
+			if conv == nil {
+				return ErrNoConversation
+			}
+			for _, s := range states {
+				if conv.State() == s || (s == Any && conv.State() != Default) {
+					return next(c)
+				}
+			}
+			return tele.Continue
+		}
+	}
+}
+
+const contextKey = "fsm"
+
+// Get returns the conversation of the current update,
+// or nil if the machine middleware hasn't been applied.
+func Get(c tele.Context) *Conversation {
+	conv, _ := c.Get(contextKey).(*Conversation)
+	return conv
+}
+
+// Conversation is the state and data of the current conversation.
+type Conversation struct {
+	m   *Machine
+	key Key
+
+	mu  sync.Mutex
+	rec *Record
+}
+
+// Key returns the conversation key.
+func (conv *Conversation) Key() Key {
+	return conv.key
+}
+
+// State returns the current state.
+func (conv *Conversation) State() State {
+	conv.mu.Lock()
+	defer conv.mu.Unlock()
+	return conv.rec.State
+}
+
+// Set moves the conversation to the given state and saves it.
+// Setting the Default state finishes the conversation.
+func (conv *Conversation) Set(s State) error {
+	if s == Default {
+		return conv.Finish()
+	}
+
+	conv.mu.Lock()
+	defer conv.mu.Unlock()
+
+	conv.rec.State = s
+	return conv.save()
+}
+
+// Put stores the JSON-encoded value under the field and saves it.
+func (conv *Conversation) Put(field string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	conv.mu.Lock()
+	defer conv.mu.Unlock()
+
+	if conv.rec.Data == nil {
+		conv.rec.Data = make(map[string]json.RawMessage)
+	}
+	conv.rec.Data[field] = data
+	return conv.save()
+}
+
+// Data decodes the value stored under the field into v.
+// It reports whether the field was found.
+func (conv *Conversation) Data(field string, v interface{}) (bool, error) {
+	conv.mu.Lock()
+	data, ok := conv.rec.Data[field]
+	conv.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+// Finish resets the conversation to the Default state and removes its data.
+func (conv *Conversation) Finish() error {
+	conv.mu.Lock()
+	defer conv.mu.Unlock()
+
+	conv.rec = &Record{}
+	return conv.m.storage.Delete(conv.key)
+}
+
+func (conv *Conversation) save() error {
+	conv.rec.Updated = time.Now()
+	return conv.m.storage.Set(conv.key, conv.rec)
+}
+
+// extractEndpoint mirrors the telebot one for the supported endpoint types.
+func extractEndpoint(endpoint interface{}) string {
+	switch end := endpoint.(type) {
+	case string:
+		return end
+	case tele.CallbackEndpoint:
+		return end.CallbackUnique()
+	}
+	return ""
+}
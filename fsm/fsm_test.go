@@ -0,0 +1,201 @@
+package fsm
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+func newTestBot(t *testing.T) *tele.Bot {
+	b, err := tele.NewBot(tele.Settings{Synchronous: true, Offline: true})
+	require.NoError(t, err)
+	return b
+}
+
+func text(s string) tele.Update {
+	return tele.Update{Message: &tele.Message{
+		Text:   s,
+		Chat:   &tele.Chat{ID: 1},
+		Sender: &tele.User{ID: 2},
+	}}
+}
+
+func TestMachine(t *testing.T) {
+	b := newTestBot(t)
+	storage := NewMemoryStorage()
+
+	m := New(b, storage)
+	m.CancelCommand = "/cancel"
+
+	var trace []string
+	m.OnCancel = func(c tele.Context) error {
+		trace = append(trace, "cancel")
+		return nil
+	}
+
+	b.Handle("/signup", func(c tele.Context) error {
+		return Get(c).Set("await_email")
+	}, m.Middleware())
+
+	m.Handle(tele.OnText, Default, func(c tele.Context) error {
+		trace = append(trace, "default:"+c.Text())
+		return nil
+	})
+	m.Handle(tele.OnText, "await_email", func(c tele.Context) error {
+		conv := Get(c)
+		require.NoError(t, conv.Put("email", c.Text()))
+		return conv.Set("await_name")
+	})
+	m.Handle(tele.OnText, Any, func(c tele.Context) error {
+		var email string
+		ok, err := Get(c).Data("email", &email)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		trace = append(trace, "any:"+email+":"+c.Text())
+		return Get(c).Finish()
+	})
+
+	b.ProcessUpdate(text("hi"))
+	b.ProcessUpdate(text("/signup"))
+
+	rec, _ := storage.Get(Key{ChatID: 1, UserID: 2})
+	require.NotNil(t, rec)
+	assert.Equal(t, State("await_email"), rec.State)
+
+	b.ProcessUpdate(text("jon@snow.com"))
+	b.ProcessUpdate(text("Jon"))
+	b.ProcessUpdate(text("bye"))
+
+	b.ProcessUpdate(text("/signup"))
+	b.ProcessUpdate(text("/cancel"))
+
+	assert.Equal(t, []string{
+		"default:hi",
+		"any:jon@snow.com:Jon",
+		"default:bye",
+		"cancel",
+	}, trace)
+
+	rec, _ = storage.Get(Key{ChatID: 1, UserID: 2})
+	assert.Nil(t, rec)
+}
+
+func TestMachineTimeout(t *testing.T) {
+	b := newTestBot(t)
+	storage := NewMemoryStorage()
+
+	m := New(b, storage)
+	m.Timeout = time.Minute
+
+	var timedOut bool
+	m.OnTimeout = func(c tele.Context) error {
+		timedOut = true
+		return nil
+	}
+
+	storage.Set(Key{ChatID: 1, UserID: 2}, &Record{
+		State:   "await_email",
+		Updated: time.Now().Add(-time.Hour),
+	})
+
+	b.Use(m.Middleware())
+	b.Handle(tele.OnText, func(c tele.Context) error {
+		assert.Equal(t, Default, Get(c).State())
+		return nil
+	}, In(Default))
+
+	b.ProcessUpdate(text("hi"))
+	assert.True(t, timedOut)
+}
+
+func TestFileStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fsm.json")
+
+	s, err := NewFileStorage(path)
+	require.NoError(t, err)
+
+	key := Key{ChatID: -100, UserID: 2}
+	require.NoError(t, s.Set(key, &Record{State: "await_email"}))
+
+	s, err = NewFileStorage(path)
+	require.NoError(t, err)
+
+	rec, err := s.Get(key)
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	assert.Equal(t, State("await_email"), rec.State)
+
+	require.NoError(t, s.Delete(key))
+	rec, err = s.Get(key)
+	require.NoError(t, err)
+	assert.Nil(t, rec)
+}
+
+func TestMachineMiddleware(t *testing.T) {
+	b := newTestBot(t)
+	m := New(b, NewMemoryStorage())
+
+	var trace []string
+	mw := func(name string) tele.MiddlewareFunc {
+		return func(next tele.HandlerFunc) tele.HandlerFunc {
+			return func(c tele.Context) error {
+				trace = append(trace, name)
+				return next(c)
+			}
+		}
+	}
+
+	m.Handle(tele.OnText, Default, func(c tele.Context) error {
+		trace = append(trace, "default")
+		return Get(c).Set("await_name")
+	}, mw("mw1"))
+	m.Handle(tele.OnText, "await_name", func(c tele.Context) error {
+		trace = append(trace, "name")
+		return Get(c).Set("await_age")
+	}, mw("mw2"))
+
+	// no handler of the state, the update goes further
+	b.Handle(tele.OnMedia, func(c tele.Context) error {
+		trace = append(trace, "media")
+		return nil
+	})
+	m.Handle(tele.OnPhoto, "await_name", func(c tele.Context) error {
+		return nil
+	})
+
+	b.ProcessUpdate(text("hi"))
+	b.ProcessUpdate(text("Jon"))
+	b.ProcessUpdate(text("42"))
+	b.ProcessUpdate(tele.Update{Message: &tele.Message{
+		Photo:  &tele.Photo{},
+		Chat:   &tele.Chat{ID: 1},
+		Sender: &tele.User{ID: 2},
+	}})
+
+	assert.Equal(t, []string{"mw1", "default", "mw2", "name", "media"}, trace)
+}
+
+func TestIn(t *testing.T) {
+	b := newTestBot(t)
+	m := New(b, NewMemoryStorage())
+
+	var trace []string
+	b.Use(m.Middleware())
+	b.Handle("/start", func(c tele.Context) error {
+		trace = append(trace, "start")
+		return nil
+	}, In("await_name"))
+	b.Handle(tele.OnText, func(c tele.Context) error {
+		trace = append(trace, "text")
+		return nil
+	})
+
+	b.ProcessUpdate(text("/start"))
+	assert.Equal(t, []string{"text"}, trace)
+}
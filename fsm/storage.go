@@ -0,0 +1,170 @@
+package fsm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Storage keeps the conversations between updates.
+type Storage interface {
+	// Get returns the stored record, or nil if there is no such.
+	Get(key Key) (*Record, error)
+
+	// Set saves the record.
+	Set(key Key, r *Record) error
+
+	// Delete removes the record. Deleting a missing one is not an error.
+	Delete(key Key) error
+}
+
+// Record is the stored state of the conversation.
+type Record struct {
+	State   State                      `json:"state"`
+	Data    map[string]json.RawMessage `json:"data,omitempty"`
+	Updated time.Time                  `json:"updated"`
+}
+
+func (r *Record) clone() *Record {
+	cp := &Record{State: r.State, Updated: r.Updated}
+	if r.Data != nil {
+		cp.Data = make(map[string]json.RawMessage, len(r.Data))
+		for k, v := range r.Data {
+			cp.Data[k] = v
+		}
+	}
+	return cp
+}
+
+// MemoryStorage keeps the conversations in memory.
+// They are lost once the process exits.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	records map[Key]*Record
+}
+
+// NewMemoryStorage returns a new empty in-memory storage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{records: make(map[Key]*Record)}
+}
+
+// Get implements Storage.
+func (s *MemoryStorage) Get(key Key) (*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.records[key]
+	if !ok {
+		return nil, nil
+	}
+	return r.clone(), nil
+}
+
+// Set implements Storage.
+func (s *MemoryStorage) Set(key Key, r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = r.clone()
+	return nil
+}
+
+// Delete implements Storage.
+func (s *MemoryStorage) Delete(key Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// FileStorage keeps the conversations in memory and persists
+// them into a JSON file on every change.
+type FileStorage struct {
+	path string
+	mem  *MemoryStorage
+	mu   sync.Mutex // serializes writes
+}
+
+// NewFileStorage returns a storage backed by the file at the path.
+// The conversations are loaded from it if the file exists.
+func NewFileStorage(path string) (*FileStorage, error) {
+	s := &FileStorage{path: path, mem: NewMemoryStorage()}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records map[string]*Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	for k, r := range records {
+		key, err := parseKey(k)
+		if err != nil {
+			return nil, err
+		}
+		s.mem.records[key] = r
+	}
+	return s, nil
+}
+
+// Get implements Storage.
+func (s *FileStorage) Get(key Key) (*Record, error) {
+	return s.mem.Get(key)
+}
+
+// Set implements Storage.
+func (s *FileStorage) Set(key Key, r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mem.Set(key, r)
+	return s.flush()
+}
+
+// Delete implements Storage.
+func (s *FileStorage) Delete(key Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mem.Delete(key)
+	return s.flush()
+}
+
+// flush atomically rewrites the file with the current records.
+func (s *FileStorage) flush() error {
+	s.mem.mu.RLock()
+	records := make(map[string]*Record, len(s.mem.records))
+	for k, r := range s.mem.records {
+		records[k.String()] = r
+	}
+	data, err := json.Marshal(records)
+	s.mem.mu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
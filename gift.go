@@ -0,0 +1,159 @@
+package telebot
+
+import "encoding/json"
+
+// Gift represents a gift that can be sent by the bot.
+type Gift struct {
+	// Unique identifier of the gift.
+	ID string `json:"id"`
+
+	// The sticker that represents the gift.
+	Sticker *Sticker `json:"sticker"`
+
+	// The number of Telegram Stars that must be paid to send the sticker.
+	StarCount int `json:"star_count"`
+
+	// (Optional) The number of Telegram Stars that must be paid
+	// to upgrade the gift to a unique one.
+	UpgradeStarCount int `json:"upgrade_star_count,omitempty"`
+
+	// (Optional) The total number of the gifts of this type that can be sent;
+	// for limited gifts only.
+	TotalCount int `json:"total_count,omitempty"`
+
+	// (Optional) The number of remaining gifts of this type that can be sent;
+	// for limited gifts only.
+	RemainingCount int `json:"remaining_count,omitempty"`
+}
+
+// GiftInfo describes a service message about a regular gift that was sent or received.
+type GiftInfo struct {
+	// Information about the gift.
+	Gift Gift `json:"gift"`
+
+	// (Optional) Unique identifier of the received gift for the bot;
+	// only present for gifts received on behalf of business accounts.
+	OwnedGiftID string `json:"owned_gift_id,omitempty"`
+
+	// (Optional) Number of Telegram Stars that can be claimed by
+	// the receiver by converting the gift.
+	ConvertStarCount int `json:"convert_star_count,omitempty"`
+
+	// (Optional) Number of Telegram Stars that were prepaid
+	// by the sender for the ability to upgrade the gift.
+	PrepaidUpgradeStarCount int `json:"prepaid_upgrade_star_count,omitempty"`
+
+	// (Optional) True, if the gift can be upgraded to a unique gift.
+	CanBeUpgraded bool `json:"can_be_upgraded,omitempty"`
+
+	// (Optional) Text of the message that was added to the gift.
+	Text string `json:"text,omitempty"`
+
+	// (Optional) Special entities that appear in the text.
+	Entities Entities `json:"entities,omitempty"`
+
+	// (Optional) True, if the sender and gift text are shown
+	// only to the gift receiver.
+	Private bool `json:"is_private,omitempty"`
+}
+
+// UniqueGift describes a unique gift that was upgraded from a regular gift.
+type UniqueGift struct {
+	// Human-readable name of the regular gift from which this unique gift was upgraded.
+	BaseName string `json:"base_name"`
+
+	// Unique name of the gift. This name can be used in
+	// https://t.me/nft/... links and story areas.
+	Name string `json:"name"`
+
+	// Unique number of the upgraded gift among gifts upgraded from the same regular gift.
+	Number int `json:"number"`
+}
+
+// UniqueGiftInfo describes a service message about a unique gift that was sent or received.
+type UniqueGiftInfo struct {
+	// Information about the gift.
+	Gift UniqueGift `json:"gift"`
+
+	// Origin of the gift. Currently, either “upgrade” or “transfer”.
+	Origin string `json:"origin"`
+
+	// (Optional) Unique identifier of the received gift for the bot;
+	// only present for gifts received on behalf of business accounts.
+	OwnedGiftID string `json:"owned_gift_id,omitempty"`
+
+	// (Optional) Number of Telegram Stars that must be paid to transfer the gift.
+	TransferStarCount int `json:"transfer_star_count,omitempty"`
+}
+
+// GiftOptions represents the optional parameters of SendGift.
+type GiftOptions struct {
+	// Pass true to pay for the gift upgrade from the bot's balance,
+	// thereby making the upgrade free for the receiver.
+	PayForUpgrade bool
+
+	// Text that will be shown along with the gift; 0-128 characters.
+	Text string
+
+	// Mode for parsing entities in the text.
+	ParseMode ParseMode
+
+	// Special entities that appear in the gift text.
+	// If set, ParseMode is ignored.
+	Entities Entities
+}
+
+// Gifts returns the list of gifts that can be sent by the bot to users
+// and channel chats.
+func (b *Bot) Gifts() ([]Gift, error) {
+	data, err := b.Raw("getAvailableGifts", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result struct {
+			Gifts []Gift `json:"gifts"`
+		}
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, wrapError(err)
+	}
+	return resp.Result.Gifts, nil
+}
+
+// SendGift sends a gift to the given user or channel chat,
+// paid from the bot's balance of Telegram Stars.
+func (b *Bot) SendGift(to Recipient, giftID string, opts ...GiftOptions) error {
+	if to == nil {
+		return ErrBadRecipient
+	}
+
+	params := map[string]string{
+		"gift_id": giftID,
+	}
+	if _, ok := to.(*User); ok {
+		params["user_id"] = to.Recipient()
+	} else {
+		params["chat_id"] = to.Recipient()
+	}
+
+	if len(opts) > 0 {
+		opt := opts[0]
+		if opt.PayForUpgrade {
+			params["pay_for_upgrade"] = "true"
+		}
+		if opt.Text != "" {
+			params["text"] = opt.Text
+		}
+		if len(opt.Entities) > 0 {
+			entities, _ := json.Marshal(opt.Entities)
+			params["text_entities"] = string(entities)
+		} else if opt.ParseMode != ModeDefault {
+			params["text_parse_mode"] = opt.ParseMode
+		}
+	}
+
+	_, err := b.Raw("sendGift", params)
+	return err
+}
@@ -3,6 +3,7 @@ package telebot
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Query is an incoming inline query. When the user sends
@@ -179,3 +180,62 @@ func inferIQR(result Result) error {
 
 	return nil
 }
+
+// PreparedInlineMessage describes an inline message to be sent
+// by a user of a Mini App.
+type PreparedInlineMessage struct {
+	// Unique identifier of the prepared message.
+	ID string `json:"id"`
+
+	// Expiration date of the prepared message, in Unix time.
+	// Expired prepared messages can no longer be used.
+	ExpirationUnixtime int64 `json:"expiration_date"`
+}
+
+// Expiration returns the moment of the prepared message expiration in local time.
+func (m *PreparedInlineMessage) Expiration() time.Time {
+	return time.Unix(m.ExpirationUnixtime, 0)
+}
+
+// PreparedInlineOptions represents the types of chats
+// the prepared message can be sent to.
+type PreparedInlineOptions struct {
+	AllowUserChats    bool `json:"allow_user_chats,omitempty"`
+	AllowBotChats     bool `json:"allow_bot_chats,omitempty"`
+	AllowGroupChats   bool `json:"allow_group_chats,omitempty"`
+	AllowChannelChats bool `json:"allow_channel_chats,omitempty"`
+}
+
+// SavePreparedInlineMessage stores a message that can be sent by the user
+// of a Mini App via the shareMessage method.
+func (b *Bot) SavePreparedInlineMessage(user *User, r Result, opts PreparedInlineOptions) (*PreparedInlineMessage, error) {
+	r.Process(b)
+	if r.ResultID() == "" {
+		r.SetResultID(fmt.Sprintf("%d", &r))
+	}
+	if err := inferIQR(r); err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"user_id":             user.Recipient(),
+		"result":              r,
+		"allow_user_chats":    opts.AllowUserChats,
+		"allow_bot_chats":     opts.AllowBotChats,
+		"allow_group_chats":   opts.AllowGroupChats,
+		"allow_channel_chats": opts.AllowChannelChats,
+	}
+
+	data, err := b.Raw("savePreparedInlineMessage", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result *PreparedInlineMessage
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, wrapError(err)
+	}
+	return resp.Result, nil
+}
@@ -57,6 +57,8 @@ func (r *ResultBase) Process(b *Bot) {
 	}
 	if r.ReplyMarkup != nil {
 		processButtons(r.ReplyMarkup.InlineKeyboard)
+		// The overflowing data is left to be rejected by Telegram.
+		_ = b.signButtons("", r.ReplyMarkup.InlineKeyboard)
 	}
 }
 
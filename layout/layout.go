@@ -282,6 +282,12 @@ func (lt *Layout) TextLocale(locale, k string, args ...interface{}) string {
 	return buf.String()
 }
 
+// HasTextLocale reports whether the locale defines the text.
+func (lt *Layout) HasTextLocale(locale, k string) bool {
+	tmpl, ok := lt.locales[locale]
+	return ok && tmpl.Lookup(k) != nil
+}
+
 // Callback returns a callback endpoint used to handle buttons.
 //
 // Example:
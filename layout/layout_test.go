@@ -145,4 +145,8 @@ func TestLayout(t *testing.T) {
 		lt.TextLocale("en", "nested.another.example", "another example"),
 		"This is another example.",
 	)
+
+	assert.True(t, lt.HasTextLocale("en", "nested.example"))
+	assert.False(t, lt.HasTextLocale("en", "missing"))
+	assert.False(t, lt.HasTextLocale("xx", "nested.example"))
 }
@@ -0,0 +1,204 @@
+package telebot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit configures the outbound scheduler of the bot.
+// Zero fields are defaulted to the limits published by Telegram.
+//
+// Outgoing messages are queued in order and delayed so that the limits
+// are never exceeded. If Telegram still responds with FloodError, the
+// request is retried after RetryAfter seconds.
+type RateLimit struct {
+	// Global is the maximum number of messages per second
+	// across all the chats, defaulted to 30.
+	Global int
+
+	// Private is the maximum number of messages per second
+	// to the same private chat, defaulted to 1.
+	Private int
+
+	// Group is the maximum number of messages per minute
+	// to the same group or channel, defaulted to 20.
+	Group int
+
+	// Retries is the maximum number of retries on FloodError, defaulted to 3.
+	// Set it to a negative value to disable retrying.
+	Retries int
+}
+
+// rateLimiter spaces outgoing messages by reserving time slots.
+// A slot is reserved under the lock, so sends are scheduled in order.
+type rateLimiter struct {
+	global  time.Duration
+	private time.Duration
+	group   time.Duration
+	retries int
+
+	mu    sync.Mutex
+	next  time.Time
+	chats map[string]time.Time
+}
+
+func newRateLimiter(rl RateLimit) *rateLimiter {
+	if rl.Global <= 0 {
+		rl.Global = 30
+	}
+	if rl.Private <= 0 {
+		rl.Private = 1
+	}
+	if rl.Group <= 0 {
+		rl.Group = 20
+	}
+	if rl.Retries == 0 {
+		rl.Retries = 3
+	}
+
+	return &rateLimiter{
+		global:  time.Second / time.Duration(rl.Global),
+		private: time.Second / time.Duration(rl.Private),
+		group:   time.Minute / time.Duration(rl.Group),
+		retries: rl.Retries,
+		chats:   make(map[string]time.Time),
+	}
+}
+
+// reserve returns the moment the message to the given chat can be sent at.
+func (l *rateLimiter) reserve(chat string) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.cleanup(now)
+
+	at := now
+	if l.next.After(at) {
+		at = l.next
+	}
+	if chat != "" {
+		if next, ok := l.chats[chat]; ok && next.After(at) {
+			at = next
+		}
+		l.chats[chat] = at.Add(l.interval(chat))
+	}
+
+	l.next = at.Add(l.global)
+	return at
+}
+
+// delay postpones all the following messages to the given chat,
+// which is necessary after Telegram has responded with FloodError.
+func (l *rateLimiter) delay(chat string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	at := time.Now().Add(d)
+	if chat == "" {
+		if at.After(l.next) {
+			l.next = at
+		}
+		return
+	}
+	if at.After(l.chats[chat]) {
+		l.chats[chat] = at
+	}
+}
+
+// cleanup removes the chats, which have no scheduled messages anymore.
+func (l *rateLimiter) cleanup(now time.Time) {
+	if len(l.chats) < 1024 {
+		return
+	}
+	for chat, next := range l.chats {
+		if next.Before(now) {
+			delete(l.chats, chat)
+		}
+	}
+}
+
+func (l *rateLimiter) interval(chat string) time.Duration {
+	if strings.HasPrefix(chat, "-") || strings.HasPrefix(chat, "@") {
+		return l.group
+	}
+	return l.private
+}
+
+// limited reports whether the method sends a message, so it's
+// a subject to the rate limits.
+func limited(method string) bool {
+	switch method {
+	case "sendChatAction":
+		return false
+	case "copyMessage", "copyMessages", "forwardMessage", "forwardMessages":
+		return true
+	}
+	return strings.HasPrefix(method, "send")
+}
+
+// schedule calls the given request function according to the rate limits,
+// retrying it on FloodError if allowed. It's a no-op wrapper if the limiter
+// is not set.
+func (b *Bot) schedule(ctx context.Context, method string, params interface{}, retry bool, do func() ([]byte, error)) ([]byte, error) {
+	l := b.limiter
+	if l == nil {
+		return do()
+	}
+
+	chat := extractChatID(params)
+	for attempt := 0; ; attempt++ {
+		if limited(method) {
+			if err := sleepContext(ctx, time.Until(l.reserve(chat))); err != nil {
+				return nil, err
+			}
+		}
+
+		data, err := do()
+
+		var flood FloodError
+		if !retry || !errors.As(err, &flood) || attempt >= l.retries {
+			return data, err
+		}
+
+		retryAfter := time.Duration(flood.RetryAfter) * time.Second
+		l.delay(chat, retryAfter)
+
+		if err := sleepContext(ctx, retryAfter); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// extractChatID returns the chat_id field of the request parameters.
+func extractChatID(params interface{}) string {
+	switch p := params.(type) {
+	case map[string]string:
+		return p["chat_id"]
+	case map[string]interface{}:
+		if id, ok := p["chat_id"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// sleepContext waits for the duration to elapse or the context to be done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,63 @@
+package telebot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(RateLimit{Global: 10, Private: 2, Group: 60})
+
+	now := time.Now()
+	first := l.reserve("1")
+	assert.WithinDuration(t, now, first, 10*time.Millisecond)
+
+	// global interval is applied to the other chats
+	assert.WithinDuration(t, first.Add(100*time.Millisecond), l.reserve("2"), 10*time.Millisecond)
+
+	// private interval is applied to the same chat
+	assert.WithinDuration(t, first.Add(500*time.Millisecond), l.reserve("1"), 10*time.Millisecond)
+
+	// group interval is a second for 60 messages per minute
+	group := l.reserve("-100")
+	assert.WithinDuration(t, group.Add(time.Second), l.reserve("-100"), 10*time.Millisecond)
+
+	assert.True(t, limited("sendMessage"))
+	assert.True(t, limited("copyMessage"))
+	assert.False(t, limited("sendChatAction"))
+	assert.False(t, limited("getMe"))
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 0","parameters":{"retry_after":0}}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{Offline: true, URL: srv.URL, RateLimit: &RateLimit{}})
+	require.NoError(t, err)
+
+	msg, err := b.Send(&Chat{ID: 1}, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, 1, msg.ID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	b, err = NewBot(Settings{Offline: true, URL: srv.URL, RateLimit: &RateLimit{Retries: -1}})
+	require.NoError(t, err)
+
+	atomic.StoreInt32(&calls, 0)
+	_, err = b.Send(&Chat{ID: 1}, "hello")
+	assert.IsType(t, FloodError{}, err)
+}
@@ -0,0 +1,102 @@
+package telebot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Login Widget authorization errors.
+var (
+	ErrLoginNoHash  = errors.New("telebot: login data is not signed")
+	ErrLoginBadHash = errors.New("telebot: login data hash mismatch")
+	ErrLoginBadDate = errors.New("telebot: login data has invalid auth date")
+	ErrLoginExpired = errors.New("telebot: login data is expired")
+)
+
+// loginFields are the fields of the login data signed by Telegram.
+var loginFields = []string{"id", "first_name", "last_name", "username", "photo_url", "auth_date"}
+
+// VerifyLogin checks the authorization data the Telegram Login Widget or
+// the Login button passes to the redirect URL, and returns the authorized user.
+// If maxAge is positive, the data created earlier than maxAge ago is rejected
+// with ErrLoginExpired. Values other than the login data fields are ignored,
+// so the redirect URL may have its own query parameters.
+func VerifyLogin(token string, values url.Values, maxAge time.Duration) (*User, error) {
+	hash, err := hex.DecodeString(values.Get("hash"))
+	if err != nil || len(hash) == 0 {
+		return nil, ErrLoginNoHash
+	}
+
+	signed := make(url.Values, len(loginFields))
+	for _, k := range loginFields {
+		if v, ok := values[k]; ok {
+			signed[k] = v
+		}
+	}
+
+	secret := sha256.Sum256([]byte(token))
+	if !hmac.Equal(hash, hmacSHA256(secret[:], []byte(dataCheckString(signed)))) {
+		return nil, ErrLoginBadHash
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, ErrLoginBadDate
+	}
+	if maxAge > 0 && time.Since(time.Unix(authDate, 0)) > maxAge {
+		return nil, ErrLoginExpired
+	}
+
+	id, err := strconv.ParseInt(values.Get("id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &User{
+		ID:        id,
+		FirstName: values.Get("first_name"),
+		LastName:  values.Get("last_name"),
+		Username:  values.Get("username"),
+	}, nil
+}
+
+type loginContextKey struct{}
+
+// LoginAuth returns an HTTP middleware, which verifies the login data passed
+// in the query of the request. Requests without valid data are answered with
+// 401 Unauthorized. Use LoginUserFrom to access the authorized user:
+//
+//	mux.Handle("/login", tele.LoginAuth(token, 24*time.Hour)(dashboard))
+//
+//	func dashboard(w http.ResponseWriter, r *http.Request) {
+//		user := tele.LoginUserFrom(r.Context())
+//		...
+//	}
+func LoginAuth(token string, maxAge time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := VerifyLogin(token, r.URL.Query(), maxAge)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), loginContextKey{}, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// LoginUserFrom returns the user authorized by
+// the LoginAuth middleware, or nil if there is no such.
+func LoginUserFrom(ctx context.Context) *User {
+	user, _ := ctx.Value(loginContextKey{}).(*User)
+	return user
+}
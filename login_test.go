@@ -0,0 +1,83 @@
+package telebot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signLogin(token string, values url.Values) url.Values {
+	secret := sha256.Sum256([]byte(token))
+	values.Set("hash", hex.EncodeToString(hmacSHA256(secret[:], []byte(dataCheckString(values)))))
+	return values
+}
+
+func TestVerifyLogin(t *testing.T) {
+	const token = "123:secret"
+
+	values := signLogin(token, url.Values{
+		"id":         {"42"},
+		"first_name": {"John"},
+		"username":   {"john"},
+		"auth_date":  {strconv.FormatInt(time.Now().Unix(), 10)},
+	})
+
+	user, err := VerifyLogin(token, values, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, &User{ID: 42, FirstName: "John", Username: "john"}, user)
+
+	_, err = VerifyLogin("123:other", values, time.Hour)
+	assert.Equal(t, ErrLoginBadHash, err)
+
+	values.Set("id", "43")
+	_, err = VerifyLogin(token, values, time.Hour)
+	assert.Equal(t, ErrLoginBadHash, err)
+
+	_, err = VerifyLogin(token, url.Values{"id": {"42"}}, time.Hour)
+	assert.Equal(t, ErrLoginNoHash, err)
+
+	values = signLogin(token, url.Values{"id": {"42"}, "auth_date": {"today"}})
+	_, err = VerifyLogin(token, values, time.Hour)
+	assert.Equal(t, ErrLoginBadDate, err)
+
+	values = signLogin(token, url.Values{
+		"id":        {"42"},
+		"auth_date": {strconv.FormatInt(time.Now().Add(-2*time.Hour).Unix(), 10)},
+	})
+	_, err = VerifyLogin(token, values, time.Hour)
+	assert.Equal(t, ErrLoginExpired, err)
+}
+
+func TestLoginAuth(t *testing.T) {
+	const token = "123:secret"
+
+	h := LoginAuth(token, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(LoginUserFrom(r.Context()).FirstName))
+	}))
+
+	values := signLogin(token, url.Values{
+		"id":         {"42"},
+		"first_name": {"John"},
+		"auth_date":  {strconv.FormatInt(time.Now().Unix(), 10)},
+	})
+
+	// own parameters of the redirect URL aren't signed
+	values.Set("next", "/dashboard")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login?"+values.Encode(), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "John", w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login?id=42", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,93 @@
+package telebot
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Predicate is an endpoint matching the updates, which it returns true for.
+//
+//	b.Handle(tele.Predicate(func(c tele.Context) bool {
+//		return c.Message() != nil && c.Message().Dice != nil
+//	}), onDice)
+type Predicate func(Context) bool
+
+// CallbackRegexp is an endpoint matching the callback data by
+// the regular expression. See MatchCallback.
+type CallbackRegexp struct {
+	rx *regexp.Regexp
+}
+
+// MatchCallback returns the endpoint matching the callback data by the rx.
+// The data of the unique buttons is matched as "<unique>|<data>".
+//
+//	b.Handle(tele.MatchCallback(regexp.MustCompile(`^buy\|(\d+)$`)), func(c tele.Context) error {
+//		id := c.Matches()[1]
+//		...
+//	})
+func MatchCallback(rx *regexp.Regexp) CallbackRegexp {
+	return CallbackRegexp{rx: rx}
+}
+
+// matcher is the handler of the regexp or predicate endpoint.
+type matcher struct {
+	match   func(c Context) ([]string, bool)
+	handler HandlerFunc
+}
+
+// newMatcher returns the matcher of the endpoint, if it's a
+// *regexp.Regexp matching the text of the new messages,
+// CallbackRegexp or Predicate.
+func newMatcher(endpoint interface{}, h HandlerFunc) (matcher, bool) {
+	var match func(c Context) ([]string, bool)
+
+	switch end := endpoint.(type) {
+	case *regexp.Regexp:
+		match = func(c Context) ([]string, bool) {
+			msg := c.Update().Message
+			if msg == nil || msg.Text == "" {
+				return nil, false
+			}
+			m := end.FindStringSubmatch(msg.Text)
+			return m, m != nil
+		}
+	case CallbackRegexp:
+		match = func(c Context) ([]string, bool) {
+			if c.Callback() == nil {
+				return nil, false
+			}
+			m := end.rx.FindStringSubmatch(strings.TrimPrefix(c.Callback().Data, "\f"))
+			return m, m != nil
+		}
+	case Predicate:
+		match = func(c Context) ([]string, bool) {
+			return nil, end(c)
+		}
+	case func(Context) bool:
+		return newMatcher(Predicate(end), h)
+	default:
+		return matcher{}, false
+	}
+
+	return matcher{match: match, handler: h}, true
+}
+
+const matchesKey = "matches"
+
+// handleMatchers runs the handlers of the matching regexp or predicate
+// endpoints in the order of their registration, until one of them
+// doesn't return Continue.
+func (b *Bot) handleMatchers(c Context) bool {
+	for _, m := range *b.matchers {
+		matches, ok := m.match(c)
+		if !ok {
+			continue
+		}
+		c.Set(matchesKey, matches)
+		if b.run(m.handler, c) {
+			return true
+		}
+	}
+	c.Set(matchesKey, nil)
+	return false
+}
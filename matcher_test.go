@@ -0,0 +1,71 @@
+package telebot
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchers(t *testing.T) {
+	b, err := NewBot(Settings{Synchronous: true, Offline: true})
+	require.NoError(t, err)
+
+	var trace []string
+	handler := func(name string) HandlerFunc {
+		return func(c Context) error {
+			trace = append(trace, name)
+			if m := c.Matches(); m != nil {
+				trace = append(trace, m[1:]...)
+			}
+			return nil
+		}
+	}
+
+	b.Handle("order #1", handler("exact"))
+	b.Handle(regexp.MustCompile(`^order #(\d+)$`), handler("order"))
+	b.Handle(regexp.MustCompile(`^order`), handler("shadowed"))
+	b.Handle(MatchCallback(regexp.MustCompile(`^buy\|(\d+)$`)), handler("buy"))
+	b.Handle(Predicate(func(c Context) bool {
+		return c.Message() != nil && c.Message().Dice != nil
+	}), handler("dice"))
+	b.Handle(func(c Context) bool {
+		return c.Update().EditedMessage != nil
+	}, handler("edited"))
+	b.Handle(OnText, handler("text"))
+	b.Handle(OnCallback, handler("callback"))
+	b.Handle(OnDice, handler("unreachable"))
+	b.Handle(&Btn{Unique: "sell"}, handler("sell"))
+
+	text := func(s string) {
+		b.ProcessUpdate(Update{Message: &Message{Text: s, Chat: &Chat{ID: 1}}})
+	}
+	callback := func(data string) {
+		b.ProcessUpdate(Update{Callback: &Callback{Data: data}})
+	}
+
+	text("order #1")
+	text("order #42")
+	text("order")
+	text("hello")
+	callback("\fbuy|7")
+	callback("\fsell|7")
+	callback("buy|8")
+	callback("other")
+	b.ProcessUpdate(Update{Message: &Message{Dice: &Dice{}, Chat: &Chat{ID: 1}}})
+	b.ProcessUpdate(Update{EditedMessage: &Message{Text: "order #5", Chat: &Chat{ID: 1}}})
+
+	assert.Equal(t, []string{
+		"exact",
+		"order", "42",
+		"shadowed",
+		"text",
+		"buy", "7",
+		"sell",
+		"buy", "8",
+		"callback",
+		"dice",
+		"edited",
+	}, trace)
+}
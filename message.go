@@ -179,6 +179,21 @@ type Message struct {
 	// (Optional) Service message: a giveaway without public winners was completed.
 	GiveawayCompleted *GiveawayCompleted `json:"giveaway_completed"`
 
+	// (Optional) Service message: a regular gift was sent or received.
+	Gift *GiftInfo `json:"gift"`
+
+	// (Optional) Service message: a unique gift was sent or received.
+	UniqueGift *UniqueGiftInfo `json:"unique_gift"`
+
+	// (Optional) Message is a checklist.
+	Checklist *Checklist `json:"checklist"`
+
+	// (Optional) Service message: some tasks in a checklist were marked as done or not done.
+	ChecklistTasksDone *ChecklistTasksDone `json:"checklist_tasks_done"`
+
+	// (Optional) Service message: tasks were added to a checklist.
+	ChecklistTasksAdded *ChecklistTasksAdded `json:"checklist_tasks_added"`
+
 	// (Optional) Unique identifier of the business connection from which the message
 	// was received. If non-empty, the message belongs to a chat of the corresponding
 	// business account that is independent from any potential bot chat which might
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"log"
+	"time"
 
 	tele "gopkg.in/telebot.v4"
 )
@@ -65,3 +67,22 @@ func Recover(onError ...RecoverFunc) tele.MiddlewareFunc {
 		}
 	}
 }
+
+// Timeout returns a middleware that binds the handler to the standard
+// context with the given timeout. Once it's elapsed, all the in-flight
+// API calls made through the tele.Context are aborted.
+func Timeout(d time.Duration) tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			parent := c.StdContext()
+
+			ctx, cancel := context.WithTimeout(parent, d)
+			defer cancel()
+
+			c.SetStdContext(ctx)
+			defer c.SetStdContext(parent)
+
+			return next(c)
+		}
+	}
+}
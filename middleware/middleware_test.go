@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,3 +30,106 @@ func TestRecover(t *testing.T) {
 		Recover(onError)(h)(nil)
 	})
 }
+
+func TestTimeout(t *testing.T) {
+	c := b.NewContext(tele.Update{})
+	parent := c.StdContext()
+
+	h := func(c tele.Context) error {
+		deadline, ok := c.StdContext().Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+		return nil
+	}
+
+	require.NoError(t, Timeout(time.Minute)(h)(c))
+	assert.Equal(t, parent, c.StdContext())
+}
+
+func TestSession(t *testing.T) {
+	type session struct {
+		Visits int `json:"visits"`
+	}
+
+	file, err := NewFileSessionStore(t.TempDir())
+	require.NoError(t, err)
+
+	for name, store := range map[string]SessionStore{
+		"memory": NewMemorySessionStore(),
+		"file":   file,
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, err := tele.NewBot(tele.Settings{Synchronous: true, Offline: true})
+			require.NoError(t, err)
+
+			b.Use(Session(SessionConfig{
+				Store: store,
+				Key:   ChatSenderKey,
+				New:   func() interface{} { return &session{} },
+			}))
+
+			var visits int
+			b.Handle("/visit", func(c tele.Context) error {
+				s := GetSession(c).(*session)
+				s.Visits++
+				visits = s.Visits
+				return nil
+			})
+
+			upd := tele.Update{Message: &tele.Message{
+				Text:   "/visit",
+				Chat:   &tele.Chat{ID: 1},
+				Sender: &tele.User{ID: 2},
+			}}
+
+			b.ProcessUpdate(upd)
+			b.ProcessUpdate(upd)
+			assert.Equal(t, 2, visits)
+
+			data, err := store.Get("1:2")
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"visits":2}`, string(data))
+
+			require.NoError(t, store.Delete("1:2"))
+			b.ProcessUpdate(upd)
+			assert.Equal(t, 1, visits)
+		})
+	}
+}
+
+func TestSessionConcurrent(t *testing.T) {
+	type session struct {
+		Visits int `json:"visits"`
+	}
+
+	b, err := tele.NewBot(tele.Settings{Synchronous: true, Offline: true})
+	require.NoError(t, err)
+
+	store := NewMemorySessionStore()
+	b.Use(Session(SessionConfig{
+		Store: store,
+		New:   func() interface{} { return &session{} },
+	}))
+	b.Handle("/visit", func(c tele.Context) error {
+		GetSession(c).(*session).Visits++
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.ProcessUpdate(tele.Update{Message: &tele.Message{
+				Text:   "/visit",
+				Sender: &tele.User{ID: 1},
+			}})
+		}()
+	}
+	wg.Wait()
+
+	data, err := store.Get("1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"visits":20}`, string(data))
+}
@@ -0,0 +1,289 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// SessionStore keeps the encoded sessions between updates.
+type SessionStore interface {
+	// Get returns the stored session, or nil if there is no such.
+	Get(key string) ([]byte, error)
+
+	// Set saves the session.
+	Set(key string, data []byte) error
+
+	// Delete removes the session. Deleting a missing one is not an error.
+	Delete(key string) error
+}
+
+// SessionKey extracts the session key from the context.
+// An empty key means the update has no session.
+type SessionKey func(tele.Context) string
+
+// SenderKey keeps a session per user.
+func SenderKey(c tele.Context) string {
+	if user := c.Sender(); user != nil {
+		return strconv.FormatInt(user.ID, 10)
+	}
+	return ""
+}
+
+// ChatKey keeps a session per chat.
+func ChatKey(c tele.Context) string {
+	if chat := c.Chat(); chat != nil {
+		return strconv.FormatInt(chat.ID, 10)
+	}
+	return ""
+}
+
+// ChatSenderKey keeps a session per user in every chat.
+func ChatSenderKey(c tele.Context) string {
+	chat, user := ChatKey(c), SenderKey(c)
+	if chat == "" || user == "" {
+		return ""
+	}
+	return chat + ":" + user
+}
+
+// BusinessKey keeps a session per business connection.
+func BusinessKey(c tele.Context) string {
+	u := c.Update()
+	switch {
+	case u.BusinessConnection != nil:
+		return u.BusinessConnection.ID
+	case u.BusinessMessage != nil:
+		return u.BusinessMessage.BusinessConnectionID
+	case u.EditedBusinessMessage != nil:
+		return u.EditedBusinessMessage.BusinessConnectionID
+	case u.DeletedBusinessMessages != nil:
+		return u.DeletedBusinessMessages.BusinessConnectionID
+	}
+	return ""
+}
+
+// SessionConfig defines config for Session middleware.
+type SessionConfig struct {
+	// Store keeps the sessions. Required.
+	Store SessionStore
+
+	// Key extracts the session key, defaulted to SenderKey.
+	Key SessionKey
+
+	// New returns a pointer to the new empty session, which
+	// the stored one is decoded into. Required.
+	New func() interface{}
+}
+
+const sessionKey = "session"
+
+// Session returns a middleware that loads the session before the handler
+// and saves it afterwards if it has changed. Sessions are JSON-encoded.
+// The updates of the same session are handled one at a time, so they don't
+// overwrite each other. Stores shared by several processes aren't locked.
+// Use GetSession to access it from the handler:
+//
+//	type UserSession struct{ Visits int }
+//
+//	b.Use(middleware.Session(middleware.SessionConfig{
+//		Store: middleware.NewMemorySessionStore(),
+//		New:   func() interface{} { return &UserSession{} },
+//	}))
+//
+//	b.Handle("/visit", func(c tele.Context) error {
+//		s := middleware.GetSession(c).(*UserSession)
+//		s.Visits++
+//		return c.Send(strconv.Itoa(s.Visits))
+//	})
+func Session(v SessionConfig) tele.MiddlewareFunc {
+	if v.Store == nil || v.New == nil {
+		panic("telebot/middleware/session: Store and New are required")
+	}
+	if v.Key == nil {
+		v.Key = SenderKey
+	}
+
+	locks := newKeyLocks()
+
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			key := v.Key(c)
+			if key == "" {
+				return next(c)
+			}
+
+			locks.lock(key)
+			defer locks.unlock(key)
+
+			data, err := v.Store.Get(key)
+			if err != nil {
+				return err
+			}
+
+			s := v.New()
+			if data != nil {
+				if err := json.Unmarshal(data, s); err != nil {
+					return err
+				}
+			}
+
+			c.Set(sessionKey, s)
+			if err := next(c); err != nil {
+				return err
+			}
+
+			changed, err := json.Marshal(s)
+			if err != nil {
+				return err
+			}
+			if data != nil && bytes.Equal(bytes.TrimSpace(data), changed) {
+				return nil
+			}
+			return v.Store.Set(key, changed)
+		}
+	}
+}
+
+// keyLocks is a set of mutexes by key, which are
+// removed once nobody holds or waits for them.
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newKeyLocks() *keyLocks {
+	return &keyLocks{locks: make(map[string]*keyLock)}
+}
+
+func (l *keyLocks) lock(key string) {
+	l.mu.Lock()
+	kl, ok := l.locks[key]
+	if !ok {
+		kl = &keyLock{}
+		l.locks[key] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+
+	kl.Lock()
+}
+
+func (l *keyLocks) unlock(key string) {
+	l.mu.Lock()
+	kl := l.locks[key]
+	kl.refs--
+	if kl.refs == 0 {
+		delete(l.locks, key)
+	}
+	l.mu.Unlock()
+
+	kl.Unlock()
+}
+
+// GetSession returns the session loaded by the Session middleware,
+// or nil if there is no session for the update.
+func GetSession(c tele.Context) interface{} {
+	return c.Get(sessionKey)
+}
+
+// MemorySessionStore keeps the sessions in memory.
+// They are lost once the process exits.
+type MemorySessionStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemorySessionStore returns a new empty in-memory store.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{data: make(map[string][]byte)}
+}
+
+// Get implements SessionStore.
+func (s *MemorySessionStore) Get(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data[key], nil
+}
+
+// Set implements SessionStore.
+func (s *MemorySessionStore) Set(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = data
+	return nil
+}
+
+// Delete implements SessionStore.
+func (s *MemorySessionStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+// FileSessionStore keeps every session in a separate file of the directory.
+type FileSessionStore struct {
+	dir string
+}
+
+// NewFileSessionStore returns a store backed by the directory,
+// which is created if it doesn't exist.
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileSessionStore{dir: dir}, nil
+}
+
+func (s *FileSessionStore) path(key string) string {
+	return filepath.Join(s.dir, hex.EncodeToString([]byte(key))+".json")
+}
+
+// Get implements SessionStore.
+func (s *FileSessionStore) Get(key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Set implements SessionStore. The file is replaced atomically.
+func (s *FileSessionStore) Set(key string, data []byte) error {
+	tmp, err := ioutil.TempFile(s.dir, "session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+// Delete implements SessionStore.
+func (s *FileSessionStore) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
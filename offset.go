@@ -0,0 +1,240 @@
+package telebot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OffsetStore keeps the ID of the last update processed by the bot,
+// so the LongPoller resumes from it after a restart.
+type OffsetStore interface {
+	// LoadOffset returns the saved update ID, or 0 if there is no such.
+	LoadOffset() (int, error)
+
+	// SaveOffset saves the update ID.
+	SaveOffset(id int) error
+}
+
+// MemoryOffsetStore keeps the offset in memory.
+// It's lost once the process exits.
+type MemoryOffsetStore struct {
+	mu     sync.RWMutex
+	offset int
+}
+
+// LoadOffset implements OffsetStore.
+func (s *MemoryOffsetStore) LoadOffset() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.offset, nil
+}
+
+// SaveOffset implements OffsetStore.
+func (s *MemoryOffsetStore) SaveOffset(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset = id
+	return nil
+}
+
+// FileOffsetStore keeps the offset in the file.
+type FileOffsetStore struct {
+	path string
+	mu   sync.Mutex // serializes writes
+}
+
+// NewFileOffsetStore returns a store backed by the file at the path.
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{path: path}
+}
+
+// LoadOffset implements OffsetStore.
+func (s *FileOffsetStore) LoadOffset() (int, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// SaveOffset implements OffsetStore.
+func (s *FileOffsetStore) SaveOffset(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeFileAtomic(s.path, []byte(strconv.Itoa(id)))
+}
+
+// UpdateQueue durably keeps the received updates
+// until their handlers are finished.
+type UpdateQueue interface {
+	// Push saves the update.
+	Push(u Update) error
+
+	// Pending returns the saved updates in the order of their IDs.
+	Pending() ([]Update, error)
+
+	// Ack removes the update once it's processed.
+	Ack(id int) error
+}
+
+// FileUpdateQueue keeps every update in its own file in the directory.
+type FileUpdateQueue struct {
+	dir string
+}
+
+// NewFileUpdateQueue returns a queue backed by the directory,
+// creating it if needed.
+func NewFileUpdateQueue(dir string) (*FileUpdateQueue, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileUpdateQueue{dir: dir}, nil
+}
+
+// Push implements UpdateQueue.
+func (q *FileUpdateQueue) Push(u Update) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(q.path(u.ID), data)
+}
+
+// Pending implements UpdateQueue.
+func (q *FileUpdateQueue) Pending() ([]Update, error) {
+	files, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []Update
+	for _, fi := range files {
+		name := fi.Name()
+		if fi.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimSuffix(name, ".json")); err != nil {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(q.dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		var u Update
+		if err := json.Unmarshal(data, &u); err != nil {
+			return nil, err
+		}
+		updates = append(updates, u)
+	}
+
+	sort.Slice(updates, func(i, j int) bool {
+		return updates[i].ID < updates[j].ID
+	})
+	return updates, nil
+}
+
+// Ack implements UpdateQueue.
+func (q *FileUpdateQueue) Ack(id int) error {
+	err := os.Remove(q.path(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (q *FileUpdateQueue) path(id int) string {
+	return filepath.Join(q.dir, strconv.Itoa(id)+".json")
+}
+
+// writeFileAtomic replaces the file with the data,
+// so it's never left partially written.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// offsetTracker commits the offset once the updates up to it are
+// processed, even though their handlers may finish out of order.
+type offsetTracker struct {
+	mu        sync.Mutex
+	pending   []int
+	done      map[int]bool
+	committed int
+	progress  chan struct{}
+	commit    func(id int)
+}
+
+func newOffsetTracker(committed int, commit func(id int)) *offsetTracker {
+	return &offsetTracker{
+		done:      make(map[int]bool),
+		committed: committed,
+		progress:  make(chan struct{}),
+		commit:    commit,
+	}
+}
+
+// add tracks the received update.
+func (t *offsetTracker) add(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = append(t.pending, id)
+}
+
+// finish marks the update processed and commits
+// the offset, if the previous ones are processed too.
+func (t *offsetTracker) finish(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.done[id] = true
+
+	advanced := false
+	for len(t.pending) > 0 && t.done[t.pending[0]] {
+		delete(t.done, t.pending[0])
+		t.committed = t.pending[0]
+		t.pending = t.pending[1:]
+		advanced = true
+	}
+	if !advanced {
+		return
+	}
+
+	t.commit(t.committed)
+	close(t.progress)
+	t.progress = make(chan struct{})
+}
+
+// offset returns the committed offset and the channel
+// closed once it's advanced.
+func (t *offsetTracker) offset() (int, <-chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.committed, t.progress
+}
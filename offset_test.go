@@ -0,0 +1,177 @@
+package telebot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	var commits []int
+	tracker := newOffsetTracker(10, func(id int) {
+		commits = append(commits, id)
+	})
+
+	tracker.add(11)
+	tracker.add(12)
+	tracker.add(14)
+
+	_, progress := tracker.offset()
+	tracker.finish(12)
+	tracker.finish(14)
+	assert.Empty(t, commits)
+
+	tracker.finish(11)
+	assert.Equal(t, []int{14}, commits)
+
+	// the state is kept off the comparable update
+	u := Update{ID: 1}
+	u.onDone(func() { tracker.finish(1) })
+	assert.True(t, u == u)
+
+	offset, _ := tracker.offset()
+	assert.Equal(t, 14, offset)
+	select {
+	case <-progress:
+	default:
+		t.Fatal("progress is not signaled")
+	}
+}
+
+func TestFileOffsetStore(t *testing.T) {
+	s := NewFileOffsetStore(filepath.Join(t.TempDir(), "offset"))
+
+	offset, err := s.LoadOffset()
+	require.NoError(t, err)
+	assert.Equal(t, 0, offset)
+
+	require.NoError(t, s.SaveOffset(42))
+	offset, err = s.LoadOffset()
+	require.NoError(t, err)
+	assert.Equal(t, 42, offset)
+}
+
+func TestFileUpdateQueue(t *testing.T) {
+	q, err := NewFileUpdateQueue(filepath.Join(t.TempDir(), "queue"))
+	require.NoError(t, err)
+
+	require.NoError(t, q.Push(Update{ID: 10, Message: &Message{Text: "ten"}}))
+	require.NoError(t, q.Push(Update{ID: 9}))
+
+	pending, err := q.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, 9, pending[0].ID)
+	assert.Equal(t, "ten", pending[1].Message.Text)
+
+	require.NoError(t, q.Ack(9))
+	require.NoError(t, q.Ack(9))
+
+	pending, err = q.Pending()
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+}
+
+// newReplayingBot returns the bot, whose getUpdates returns the same
+// updates twice, ignoring the offset, and then hangs until cancelled.
+// The returned channel is closed once the replay is dispatched.
+func newReplayingBot(t *testing.T, p *LongPoller, ids ...int) (*Bot, <-chan struct{}, func() []int) {
+	var updates []Update
+	for _, id := range ids {
+		updates = append(updates, Update{ID: id, Message: &Message{Text: "/start", Chat: &Chat{ID: 1}}})
+	}
+
+	var (
+		polls    int32
+		replayed = make(chan struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&polls, 1) {
+		case 1, 2:
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": updates})
+			return
+		case 3:
+			close(replayed)
+		}
+		io.Copy(ioutil.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true, Synchronous: true, Poller: p})
+	require.NoError(t, err)
+
+	var (
+		mu      sync.Mutex
+		handled []int
+	)
+	b.Handle("/start", func(c Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, c.Update().ID)
+		return nil
+	})
+
+	return b, replayed, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), handled...)
+	}
+}
+
+func TestLongPollerOffsetStore(t *testing.T) {
+	store := &MemoryOffsetStore{}
+	require.NoError(t, store.SaveOffset(1))
+
+	p := &LongPoller{OffsetStore: store}
+	b, replayed, handled := newReplayingBot(t, p, 1, 2, 3)
+
+	go b.Start()
+	<-replayed
+	require.NoError(t, b.Shutdown(context.Background()))
+
+	// the replays are dropped
+	assert.Equal(t, []int{2, 3}, handled())
+
+	offset, err := store.LoadOffset()
+	require.NoError(t, err)
+	assert.Equal(t, 3, offset)
+}
+
+func TestLongPollerQueue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewFileUpdateQueue(filepath.Join(dir, "queue"))
+	require.NoError(t, err)
+	store := NewFileOffsetStore(filepath.Join(dir, "offset"))
+
+	// left by the previous run
+	require.NoError(t, q.Push(Update{ID: 5, Message: &Message{Text: "/start", Chat: &Chat{ID: 1}}}))
+	require.NoError(t, store.SaveOffset(5))
+
+	p := &LongPoller{OffsetStore: store, Queue: q}
+	b, replayed, handled := newReplayingBot(t, p, 4, 5, 6)
+
+	go b.Start()
+	<-replayed
+	require.NoError(t, b.Shutdown(context.Background()))
+
+	assert.Equal(t, []int{5, 6}, handled())
+
+	pending, err := q.Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	offset, err := store.LoadOffset()
+	require.NoError(t, err)
+	assert.Equal(t, 6, offset)
+}
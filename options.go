@@ -37,6 +37,9 @@ const (
 
 	// IgnoreThread is used to ignore the thread when responding to a message via context.
 	IgnoreThread
+
+	// Split = SendOptions.Split
+	Split
 )
 
 // Placeholder is used to set input field placeholder as a send option.
@@ -95,6 +98,11 @@ type SendOptions struct {
 
 	// Unique identifier of the message effect to be added to the message; for private chats only
 	EffectID string
+
+	// Split sends the text or caption exceeding the Telegram limits as
+	// multiple messages, split by paragraphs, lines or words. Only the
+	// last message has the reply markup.
+	Split bool
 }
 
 func (og *SendOptions) copy() *SendOptions {
@@ -147,6 +155,8 @@ func (b *Bot) extractOptions(how []interface{}) *SendOptions {
 				opts.ReplyMarkup.RemoveKeyboard = true
 			case Protected:
 				opts.Protected = true
+			case Split:
+				opts.Split = true
 			default:
 				panic("telebot: unsupported flag-option")
 			}
@@ -162,9 +172,9 @@ func (b *Bot) extractOptions(how []interface{}) *SendOptions {
 	return opts
 }
 
-func (b *Bot) embedSendOptions(params map[string]string, opt *SendOptions) {
+func (b *Bot) embedSendOptions(params map[string]string, opt *SendOptions) error {
 	if opt == nil {
-		return
+		return nil
 	}
 
 	if opt.ReplyTo != nil && opt.ReplyTo.ID != 0 {
@@ -200,6 +210,9 @@ func (b *Bot) embedSendOptions(params map[string]string, opt *SendOptions) {
 
 	if opt.ReplyMarkup != nil {
 		processButtons(opt.ReplyMarkup.InlineKeyboard)
+		if err := b.signButtons(params["chat_id"], opt.ReplyMarkup.InlineKeyboard); err != nil {
+			return err
+		}
 		replyMarkup, _ := json.Marshal(opt.ReplyMarkup)
 		params["reply_markup"] = string(replyMarkup)
 	}
@@ -223,6 +236,8 @@ func (b *Bot) embedSendOptions(params map[string]string, opt *SendOptions) {
 	if opt.EffectID != "" {
 		params["message_effect_id"] = opt.EffectID
 	}
+
+	return nil
 }
 
 func processButtons(keys [][]InlineButton) {
@@ -61,10 +61,60 @@ type LongPoller struct {
 	// 		poll_answer
 	//
 	AllowedUpdates []string `yaml:"allowed_updates"`
+
+	// OffsetStore, if set, keeps the LastUpdateID across restarts.
+	// Without the Queue, the offset is committed and confirmed to Telegram
+	// only once the handlers of the update and all the previous ones are
+	// finished, so the updates lost by a crash are delivered again. At most
+	// Limit updates are processed at once then.
+	OffsetStore OffsetStore `yaml:"-"`
+
+	// Queue, if set, durably keeps the received updates until their
+	// handlers are finished. The pending ones are processed first once
+	// the poller is started again.
+	Queue UpdateQueue `yaml:"-"`
 }
 
-// Poll does long polling.
+// Poll does long polling. The updates with IDs not greater
+// than the LastUpdateID are dropped as replayed.
 func (p *LongPoller) Poll(b *Bot, dest chan Update, stop chan struct{}) {
+	if p.OffsetStore != nil {
+		offset, err := p.OffsetStore.LoadOffset()
+		if err != nil {
+			b.OnError(err, nil)
+			return
+		}
+		if offset > p.LastUpdateID {
+			p.LastUpdateID = offset
+		}
+	}
+
+	var tracker *offsetTracker
+	if p.Queue != nil {
+		pending, err := p.Queue.Pending()
+		if err != nil {
+			b.OnError(err, nil)
+			return
+		}
+		for _, update := range pending {
+			if update.ID > p.LastUpdateID {
+				p.LastUpdateID = update.ID
+			}
+			update.onDone(p.ack(b, update.ID))
+			select {
+			case dest <- update:
+			case <-stop:
+				return
+			}
+		}
+	} else if p.OffsetStore != nil {
+		tracker = newOffsetTracker(p.LastUpdateID, func(id int) {
+			if err := p.OffsetStore.SaveOffset(id); err != nil {
+				b.OnError(err, nil)
+			}
+		})
+	}
+
 	for {
 		select {
 		case <-stop:
@@ -72,16 +122,65 @@ func (p *LongPoller) Poll(b *Bot, dest chan Update, stop chan struct{}) {
 		default:
 		}
 
-		updates, err := b.getUpdates(p.LastUpdateID+1, p.Limit, p.Timeout, p.AllowedUpdates)
+		offset := p.LastUpdateID
+		var progress <-chan struct{}
+		if tracker != nil {
+			offset, progress = tracker.offset()
+		}
+
+		updates, err := b.getUpdates(offset+1, p.Limit, p.Timeout, p.AllowedUpdates)
 		if err != nil {
 			b.debug(err)
 			continue
 		}
 
+		fresh := false
+	dispatch:
 		for _, update := range updates {
+			if update.ID <= p.LastUpdateID {
+				continue
+			}
+
+			switch {
+			case p.Queue != nil:
+				if err := p.Queue.Push(update); err != nil {
+					b.OnError(err, nil)
+					break dispatch
+				}
+				if p.OffsetStore != nil {
+					if err := p.OffsetStore.SaveOffset(update.ID); err != nil {
+						b.OnError(err, nil)
+					}
+				}
+				update.onDone(p.ack(b, update.ID))
+			case tracker != nil:
+				id := update.ID
+				tracker.add(id)
+				update.onDone(func() { tracker.finish(id) })
+			}
+
 			p.LastUpdateID = update.ID
+			fresh = true
 			dest <- update
 		}
+
+		// The updates in progress are fetched again, until
+		// their offset is committed.
+		if tracker != nil && !fresh && len(updates) > 0 {
+			select {
+			case <-progress:
+			case <-stop:
+				return
+			}
+		}
+	}
+}
+
+func (p *LongPoller) ack(b *Bot, id int) func() {
+	return func() {
+		if err := p.Queue.Ack(id); err != nil {
+			b.OnError(err, nil)
+		}
 	}
 }
 
@@ -128,6 +227,8 @@ func (p *MiddlewarePoller) Poll(b *Bot, dest chan Update, stop chan struct{}) {
 		case upd := <-middle:
 			if p.Filter(&upd) {
 				dest <- upd
+			} else {
+				upd.done()
 			}
 		}
 	}
@@ -0,0 +1,80 @@
+package telebot
+
+import (
+	"hash/fnv"
+	"strconv"
+	"sync"
+)
+
+// workerPool runs handlers on a fixed number of goroutines.
+// Submitting blocks while the queue is full, so the backpressure
+// propagates through the Updates channel to the poller.
+type workerPool struct {
+	workers int
+	queues  []chan func()
+	shard   bool
+	once    sync.Once
+}
+
+func newWorkerPool(workers, queue int, shard bool) *workerPool {
+	if queue <= 0 {
+		queue = workers
+	}
+
+	p := &workerPool{workers: workers, shard: shard}
+	if !shard {
+		// A single queue shared by all the workers.
+		p.queues = []chan func(){make(chan func(), queue)}
+		return p
+	}
+
+	size := queue / workers
+	if size < 1 {
+		size = 1
+	}
+
+	p.queues = make([]chan func(), workers)
+	for i := range p.queues {
+		p.queues[i] = make(chan func(), size)
+	}
+	return p
+}
+
+// start spawns the workers once the first task is submitted.
+func (p *workerPool) start() {
+	p.once.Do(func() {
+		for i := 0; i < p.workers; i++ {
+			q := p.queues[i%len(p.queues)]
+			go func() {
+				for f := range q {
+					f()
+				}
+			}()
+		}
+	})
+}
+
+// submit enqueues the task. Tasks with the same non-empty key
+// are executed in order if the pool is sharded.
+func (p *workerPool) submit(key string, f func()) {
+	p.start()
+
+	q := p.queues[0]
+	if p.shard && key != "" {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		q = p.queues[h.Sum32()%uint32(len(p.queues))]
+	}
+	q <- f
+}
+
+// shardKey returns the key the context's updates are ordered by.
+func shardKey(c Context) string {
+	if chat := c.Chat(); chat != nil {
+		return strconv.FormatInt(chat.ID, 10)
+	}
+	if user := c.Sender(); user != nil {
+		return strconv.FormatInt(user.ID, 10)
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package telebot
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPool(t *testing.T) {
+	t.Run("bounded", func(t *testing.T) {
+		b, err := NewBot(Settings{Offline: true, Workers: 2})
+		require.NoError(t, err)
+
+		var (
+			wg            sync.WaitGroup
+			running, peak int32
+			release       = make(chan struct{})
+		)
+
+		b.Handle(OnText, func(c Context) error {
+			defer wg.Done()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+
+		wg.Add(4)
+		for i := 0; i < 4; i++ {
+			go b.ProcessUpdate(Update{Message: &Message{Text: "text"}})
+		}
+		close(release)
+		wg.Wait()
+
+		assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		b, err := NewBot(Settings{Offline: true, Workers: 4, WorkerQueue: 100, ShardByChat: true})
+		require.NoError(t, err)
+
+		var (
+			wg    sync.WaitGroup
+			mu    sync.Mutex
+			order = make(map[int64][]int)
+		)
+
+		b.Handle(OnText, func(c Context) error {
+			defer wg.Done()
+			mu.Lock()
+			order[c.Chat().ID] = append(order[c.Chat().ID], c.Update().ID)
+			mu.Unlock()
+			return nil
+		})
+
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			b.ProcessUpdate(Update{ID: i, Message: &Message{Text: "text", Chat: &Chat{ID: int64(i % 3)}}})
+		}
+		wg.Wait()
+
+		for _, ids := range order {
+			assert.IsIncreasing(t, ids)
+		}
+	})
+}
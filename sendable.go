@@ -28,7 +28,9 @@ func (p *Photo) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 		"chat_id": to.Recipient(),
 		"caption": p.Caption,
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	msg, err := b.sendMedia(p, params, nil)
 	if err != nil {
@@ -51,7 +53,9 @@ func (a *Audio) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 		"title":     a.Title,
 		"file_name": a.FileName,
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	if a.Duration != 0 {
 		params["duration"] = strconv.Itoa(a.Duration)
@@ -83,7 +87,9 @@ func (d *Document) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error
 		"caption":   d.Caption,
 		"file_name": d.FileName,
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	if d.FileSize != 0 {
 		params["file_size"] = strconv.FormatInt(d.FileSize, 10)
@@ -117,7 +123,9 @@ func (s *Sticker) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error)
 		"chat_id": to.Recipient(),
 		"emoji":   s.Emoji,
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	msg, err := b.sendMedia(s, params, nil)
 	if err != nil {
@@ -137,7 +145,9 @@ func (v *Video) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 		"caption":   v.Caption,
 		"file_name": v.FileName,
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	if v.Duration != 0 {
 		params["duration"] = strconv.Itoa(v.Duration)
@@ -180,7 +190,9 @@ func (a *Animation) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, erro
 		"caption":   a.Caption,
 		"file_name": a.FileName,
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	if a.Duration != 0 {
 		params["duration"] = strconv.Itoa(a.Duration)
@@ -224,7 +236,9 @@ func (v *Voice) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 		"chat_id": to.Recipient(),
 		"caption": v.Caption,
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	if v.Duration != 0 {
 		params["duration"] = strconv.Itoa(v.Duration)
@@ -246,7 +260,9 @@ func (v *VideoNote) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, erro
 	params := map[string]string{
 		"chat_id": to.Recipient(),
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	if v.Duration != 0 {
 		params["duration"] = strconv.Itoa(v.Duration)
@@ -283,7 +299,9 @@ func (x *Location) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error
 	if x.AlertRadius != 0 {
 		params["proximity_alert_radius"] = strconv.Itoa(x.Heading)
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("sendLocation", params)
 	if err != nil {
@@ -306,7 +324,9 @@ func (v *Venue) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 		"google_place_id":   v.GooglePlaceID,
 		"google_place_type": v.GooglePlaceType,
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("sendVenue", params)
 	if err != nil {
@@ -320,7 +340,9 @@ func (v *Venue) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 func (i *Invoice) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 	params := i.params()
 	params["chat_id"] = to.Recipient()
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("sendInvoice", params)
 	if err != nil {
@@ -350,7 +372,9 @@ func (p *Poll) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 	} else if p.CloseUnixdate != 0 {
 		params["close_date"] = strconv.FormatInt(p.CloseUnixdate, 10)
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	opts, _ := json.Marshal(p.Options)
 	params["options"] = string(opts)
@@ -369,7 +393,9 @@ func (d *Dice) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 		"chat_id": to.Recipient(),
 		"emoji":   string(d.Type),
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("sendDice", params)
 	if err != nil {
@@ -385,7 +411,9 @@ func (g *Game) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 		"chat_id":         to.Recipient(),
 		"game_short_name": g.Name,
 	}
-	b.embedSendOptions(params, opt)
+	if err := b.embedSendOptions(params, opt); err != nil {
+		return nil, err
+	}
 
 	data, err := b.Raw("sendGame", params)
 	if err != nil {
@@ -0,0 +1,531 @@
+package telebot
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// Telegram limits in UTF-16 code units, counted after the entities parsing.
+const (
+	textLimit    = 4096
+	captionLimit = 1024
+)
+
+// chunk is a part of the split text.
+type chunk struct {
+	text     string
+	entities Entities
+}
+
+// markupToken is either a visible character, which may be escaped,
+// or a zero-width markup opening or closing an entity.
+type markupToken struct {
+	raw   string
+	width int
+
+	// rank of the break after the token: 1 for a word,
+	// 2 for a line and 3 for a paragraph
+	br int
+
+	open   bool
+	close  bool
+	marker string
+	closer string
+
+	// the > prefix of the quotation line
+	quoteLine bool
+}
+
+// splitText splits the text into the chunks, first of which is limited by
+// the first length and the others are by rest. It prefers to split by
+// paragraphs, then by lines and words. The entities crossing the split
+// are closed in the chunk and reopened in the next one.
+func splitText(text string, mode ParseMode, entities Entities, first, rest int) []chunk {
+	if len(entities) > 0 || mode == ModeDefault {
+		return splitEntities(text, entities, first, rest)
+	}
+
+	var tokens []markupToken
+	switch mode {
+	case ModeHTML:
+		tokens = htmlTokens(text)
+	case ModeMarkdownV2:
+		tokens = markdownTokens(text, false)
+	default:
+		tokens = markdownTokens(text, true)
+	}
+
+	starts := splitTokens(tokens, first, rest)
+
+	var (
+		chunks []chunk
+		stack  []markupToken
+	)
+	for k, s := range starts {
+		e := len(tokens)
+		if k+1 < len(starts) {
+			e = starts[k+1]
+		}
+
+		var sb strings.Builder
+		if s < e && tokens[s].quoteLine {
+			// The quotation is reopened by the line prefix itself.
+			sb.WriteString(tokens[s].raw)
+			for _, t := range stack {
+				if t.marker != ">" {
+					sb.WriteString(t.raw)
+				}
+			}
+			s++
+		} else {
+			for _, t := range stack {
+				sb.WriteString(t.raw)
+			}
+		}
+
+		for _, t := range tokens[s:e] {
+			sb.WriteString(t.raw)
+			switch {
+			case t.open:
+				stack = append(stack, t)
+			case t.close && len(stack) > 0:
+				stack = stack[:len(stack)-1]
+			}
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			sb.WriteString(stack[i].closer)
+		}
+
+		if !blank(tokens[s:e]) {
+			chunks = append(chunks, chunk{text: sb.String()})
+		}
+	}
+	return chunks
+}
+
+func splitEntities(text string, entities Entities, first, rest int) []chunk {
+	var t tokenizer
+	for _, r := range text {
+		t.char(string(r), r)
+	}
+	tokens := t.tokens
+
+	offsets := make([]int, len(tokens)+1)
+	for i, t := range tokens {
+		offsets[i+1] = offsets[i] + t.width
+	}
+
+	starts := splitTokens(tokens, first, rest)
+
+	var chunks []chunk
+	for k, s := range starts {
+		e := len(tokens)
+		if k+1 < len(starts) {
+			e = starts[k+1]
+		}
+		if blank(tokens[s:e]) {
+			continue
+		}
+
+		var sb strings.Builder
+		for _, t := range tokens[s:e] {
+			sb.WriteString(t.raw)
+		}
+
+		c := chunk{text: sb.String()}
+		from, to := offsets[s], offsets[e]
+		for _, en := range entities {
+			start, end := en.Offset, en.Offset+en.Length
+			if start < from {
+				start = from
+			}
+			if end > to {
+				end = to
+			}
+			if start < end {
+				en.Offset, en.Length = start-from, end-start
+				c.entities = append(c.entities, en)
+			}
+		}
+		chunks = append(chunks, c)
+	}
+	return chunks
+}
+
+// splitTokens returns the indices of the tokens the chunks start with.
+func splitTokens(tokens []markupToken, first, rest int) []int {
+	var (
+		starts = []int{0}
+		start  = 0
+		width  = 0
+		limit  = first
+		best   [4]int
+	)
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if width+t.width <= limit || i == start {
+			width += t.width
+			if t.br > 0 {
+				best[t.br] = i + 1
+			}
+			continue
+		}
+
+		cut := i
+		for r := 3; r > 0; r-- {
+			if best[r] > start {
+				cut = best[r]
+				break
+			}
+		}
+		// Closing markup has no width, so it stays in the chunk
+		// instead of making an empty entity in the next one.
+		for cut < len(tokens) && tokens[cut].close {
+			cut++
+		}
+		if cut == len(tokens) {
+			break
+		}
+
+		starts = append(starts, cut)
+		start, width, limit = cut, 0, rest
+		i = cut - 1
+	}
+	return starts
+}
+
+// blank reports whether the tokens have no visible characters
+// except for the spaces.
+func blank(tokens []markupToken) bool {
+	for _, t := range tokens {
+		if t.width > 0 && t.br == 0 && strings.TrimSpace(t.raw) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+type tokenizer struct {
+	tokens []markupToken
+	prev   rune
+}
+
+// char adds the visible character r written as raw.
+func (t *tokenizer) char(raw string, r rune) {
+	tok := markupToken{raw: raw, width: 1}
+	if r >= 0x10000 {
+		tok.width = 2
+	}
+
+	switch r {
+	case '\n':
+		tok.br = 2
+		if t.prev == '\n' {
+			tok.br = 3
+		}
+	case ' ', '\t':
+		tok.br = 1
+	}
+
+	t.prev = r
+	t.tokens = append(t.tokens, tok)
+}
+
+// text adds the visible text written as raw.
+func (t *tokenizer) text(raw, text string) {
+	tok := markupToken{raw: raw}
+	for _, r := range text {
+		tok.width++
+		if r >= 0x10000 {
+			tok.width++
+		}
+		t.prev = r
+	}
+	t.tokens = append(t.tokens, tok)
+}
+
+func (t *tokenizer) markup(tok markupToken) {
+	t.tokens = append(t.tokens, tok)
+}
+
+func htmlTokens(s string) []markupToken {
+	var t tokenizer
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '<':
+			j := strings.IndexByte(s[i:], '>')
+			if j < 0 {
+				break
+			}
+			tag := s[i : i+j+1]
+			i += j + 1
+
+			if strings.HasPrefix(tag, "</") {
+				t.markup(markupToken{raw: tag, close: true})
+				continue
+			}
+			name := strings.Fields(tag[1 : len(tag)-1])
+			if len(name) == 0 {
+				t.text(tag, tag)
+				continue
+			}
+			t.markup(markupToken{raw: tag, open: true, closer: "</" + name[0] + ">"})
+			continue
+		case '&':
+			if j := strings.IndexByte(s[i:], ';'); j > 0 && j < 12 {
+				raw := s[i : i+j+1]
+				t.text(raw, html.UnescapeString(raw))
+				i += j + 1
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		t.char(s[i:i+size], r)
+		i += size
+	}
+	return t.tokens
+}
+
+// markdownTokens tokenizes the MarkdownV2 text, or the legacy Markdown one,
+// which has no nested entities and escaping inside of them.
+func markdownTokens(s string, legacy bool) []markupToken {
+	var (
+		t         tokenizer
+		stack     []int
+		lineStart = true
+	)
+
+	top := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return t.tokens[stack[len(stack)-1]].marker
+	}
+	open := func(raw, marker, closer string) {
+		stack = append(stack, len(t.tokens))
+		t.markup(markupToken{raw: raw, open: true, marker: marker, closer: closer})
+	}
+	closeTop := func(raw string) {
+		stack = stack[:len(stack)-1]
+		t.markup(markupToken{raw: raw, close: true})
+	}
+	toggle := func(marker string) {
+		if top() == marker {
+			closeTop(marker)
+		} else {
+			open(marker, marker, marker)
+		}
+	}
+	inQuote := func() bool {
+		for _, i := range stack {
+			if m := t.tokens[i].marker; m == ">" || m == "**>" {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		atLineStart := lineStart
+		lineStart = false
+
+		inCode := top() == "`" || top() == "```"
+		inEntity := legacy && len(stack) > 0
+
+		switch {
+		case inCode && strings.HasPrefix(s[i:], top()) && (top() == "```" || !strings.HasPrefix(s[i:], "```")):
+			n := len(top())
+			closeTop(s[i : i+n])
+			i += n
+			continue
+
+		case c == '\\' && i+1 < len(s) && (!legacy || !inEntity):
+			r, size := utf8.DecodeRuneInString(s[i+1:])
+			t.char(s[i:i+1+size], r)
+			i += 1 + size
+			continue
+
+		case inCode:
+			// Only the escapes and the closing marker are special.
+
+		case !legacy && atLineStart && strings.HasPrefix(s[i:], "**>"):
+			open("**>", "**>", "||")
+			i += 3
+			continue
+
+		case !legacy && atLineStart && c == '>':
+			if inQuote() {
+				t.markup(markupToken{raw: ">", quoteLine: true})
+			} else {
+				open(">", ">", "")
+			}
+			i++
+			continue
+
+		case c == '\n':
+			if !legacy && top() == ">" && !strings.HasPrefix(s[i+1:], ">") {
+				closeTop("")
+			}
+			t.char("\n", '\n')
+			lineStart = true
+			i++
+			continue
+
+		case !legacy && c == '\r':
+			t.markup(markupToken{raw: "\r"})
+			i++
+			continue
+
+		case strings.HasPrefix(s[i:], "```") && !inEntity:
+			raw := "```"
+			if nl := strings.IndexByte(s[i+3:], '\n'); nl >= 0 {
+				raw = s[i : i+3+nl+1]
+			}
+			open(raw, "```", "```")
+			i += len(raw)
+			continue
+
+		case c == '`' && !inEntity:
+			open("`", "`", "`")
+			i++
+			continue
+
+		case !legacy && strings.HasPrefix(s[i:], "||"):
+			if top() == "**>" && (i+2 == len(s) || s[i+2] == '\n') {
+				closeTop("||")
+			} else {
+				toggle("||")
+			}
+			i += 2
+			continue
+
+		case !legacy && strings.HasPrefix(s[i:], "__"):
+			toggle("__")
+			i += 2
+			continue
+
+		case (c == '*' || c == '_' || (!legacy && c == '~')) && (!inEntity || top() == string(c)):
+			toggle(string(c))
+			i++
+			continue
+
+		case !legacy && strings.HasPrefix(s[i:], "!["):
+			open("![", "[", "")
+			i += 2
+			continue
+
+		case c == '[' && !inEntity:
+			open("[", "[", "")
+			i++
+			continue
+
+		case c == ']' && top() == "[" && strings.HasPrefix(s[i+1:], "("):
+			end := strings.IndexByte(s[i+2:], ')')
+			if !legacy {
+				end = indexUnescaped(s[i+2:], ')')
+			}
+			if end < 0 {
+				break
+			}
+			raw := s[i : i+3+end]
+			t.tokens[stack[len(stack)-1]].closer = raw
+			closeTop(raw)
+			i += len(raw)
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		t.char(s[i:i+size], r)
+		i += size
+	}
+	return t.tokens
+}
+
+// indexUnescaped returns the index of the first c not escaped by a backslash.
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
+
+// captionOf returns the pointer to the caption of the sendable media.
+func captionOf(s Sendable) *string {
+	switch v := s.(type) {
+	case *Photo:
+		return &v.Caption
+	case *Audio:
+		return &v.Caption
+	case *Document:
+		return &v.Caption
+	case *Video:
+		return &v.Caption
+	case *Animation:
+		return &v.Caption
+	case *Voice:
+		return &v.Caption
+	}
+	return nil
+}
+
+// chunkOptions returns the options of the i-th chunk out of n. Only the
+// first one is a reply, and only the last one has the reply markup.
+func chunkOptions(opt *SendOptions, c chunk, i, n int) *SendOptions {
+	o := *opt
+	o.Entities = c.entities
+	if i > 0 {
+		o.ReplyTo = nil
+		o.ReplyParams = nil
+		o.HasSpoiler = false
+	}
+	if i < n-1 {
+		o.ReplyMarkup = nil
+	}
+	return &o
+}
+
+func (b *Bot) sendTextSplit(to Recipient, text string, opt *SendOptions) (*Message, error) {
+	chunks := splitText(text, opt.ParseMode, opt.Entities, textLimit, textLimit)
+	if len(chunks) < 2 {
+		return b.sendText(to, text, opt)
+	}
+
+	var msg *Message
+	for i, c := range chunks {
+		var err error
+		msg, err = b.sendText(to, c.text, chunkOptions(opt, c, i, len(chunks)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+func (b *Bot) sendCaptionSplit(to Recipient, media Sendable, caption *string, opt *SendOptions) (*Message, error) {
+	chunks := splitText(*caption, opt.ParseMode, opt.Entities, captionLimit, textLimit)
+	if len(chunks) < 2 {
+		return media.Send(b, to, opt)
+	}
+
+	*caption = chunks[0].text
+	msg, err := media.Send(b, to, chunkOptions(opt, chunks[0], 0, len(chunks)))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range chunks[1:] {
+		msg, err = b.sendText(to, c.text, chunkOptions(opt, c, i+1, len(chunks)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
@@ -0,0 +1,115 @@
+package telebot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chunkTexts(chunks []chunk) []string {
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.text
+	}
+	return texts
+}
+
+func TestSplitText(t *testing.T) {
+	// paragraphs are preferred to lines and words
+	chunks := splitText("one two\nthree\n\nfour five", ModeDefault, nil, 16, 16)
+	assert.Equal(t, []string{"one two\nthree\n\n", "four five"}, chunkTexts(chunks))
+
+	chunks = splitText("one two three four", ModeDefault, nil, 9, 9)
+	assert.Equal(t, []string{"one two ", "three ", "four"}, chunkTexts(chunks))
+
+	// the first chunk may have a different limit
+	chunks = splitText("one two three four", ModeDefault, nil, 5, 100)
+	assert.Equal(t, []string{"one ", "two three four"}, chunkTexts(chunks))
+
+	// no breaks, UTF-16 surrogate pairs are kept whole
+	chunks = splitText("🔥🔥🔥", ModeDefault, nil, 3, 3)
+	assert.Equal(t, []string{"🔥", "🔥", "🔥"}, chunkTexts(chunks))
+
+	// entities crossing the split are cut
+	chunks = splitText("🔥 bold text", ModeDefault, Entities{
+		{Type: EntityBold, Offset: 3, Length: 9},
+		{Type: EntityItalic, Offset: 0, Length: 2},
+	}, 8, 8)
+	assert.Equal(t, []chunk{
+		{text: "🔥 bold ", entities: Entities{
+			{Type: EntityBold, Offset: 3, Length: 5},
+			{Type: EntityItalic, Offset: 0, Length: 2},
+		}},
+		{text: "text", entities: Entities{
+			{Type: EntityBold, Offset: 0, Length: 4},
+		}},
+	}, chunks)
+}
+
+func TestSplitMarkup(t *testing.T) {
+	chunks := splitText(`<b>bold <i>and &amp; italic</i></b> <pre><code class="language-go">x := 1</code></pre>`, ModeHTML, nil, 12, 12)
+	assert.Equal(t, []string{
+		`<b>bold <i>and &amp; </i></b>`,
+		`<b><i>italic</i></b> <pre><code class="language-go">x := </code></pre>`,
+		`<pre><code class="language-go">1</code></pre>`,
+	}, chunkTexts(chunks))
+
+	chunks = splitText("*bold _and \\_ italic_* [link text](https://go.dev/\\))", ModeMarkdownV2, nil, 12, 12)
+	assert.Equal(t, []string{
+		"*bold _and \\_ _*",
+		"*_italic_* [link ](https://go.dev/\\))",
+		"[text](https://go.dev/\\))",
+	}, chunkTexts(chunks))
+
+	chunks = splitText(">quote one\n>quote two\nend", ModeMarkdownV2, nil, 10, 10)
+	assert.Equal(t, []string{">quote one\n", ">quote two\n", "end"}, chunkTexts(chunks))
+
+	chunks = splitText(">quote one two", ModeMarkdownV2, nil, 10, 10)
+	assert.Equal(t, []string{">quote one ", ">two"}, chunkTexts(chunks))
+
+	chunks = splitText("*bold_text two* words", ModeMarkdown, nil, 10, 10)
+	assert.Equal(t, []string{"*bold_text *", "*two* words"}, chunkTexts(chunks))
+}
+
+func TestSendSplit(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []map[string]string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+
+		mu.Lock()
+		requests = append(requests, params)
+		mu.Unlock()
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{Offline: true, URL: srv.URL})
+	require.NoError(t, err)
+
+	markup := &ReplyMarkup{}
+	markup.Inline(markup.Row(markup.Data("OK", "ok")))
+
+	text := strings.Repeat("word ", 1000)
+	_, err = b.Send(&Chat{ID: 1}, text, &SendOptions{ReplyTo: &Message{ID: 5}, ReplyMarkup: markup}, Split)
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, strings.Repeat("word ", 819), requests[0]["text"])
+	assert.Empty(t, requests[0]["reply_markup"])
+	assert.NotEmpty(t, requests[0]["reply_to_message_id"])
+	assert.Equal(t, strings.Repeat("word ", 181), requests[1]["text"])
+	assert.NotEmpty(t, requests[1]["reply_markup"])
+	assert.Empty(t, requests[1]["reply_to_message_id"])
+}
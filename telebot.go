@@ -116,6 +116,15 @@ const (
 	OnBoost        = "\aboost_updated"
 	OnBoostRemoved = "\aboost_removed"
 
+	OnReaction      = "\amessage_reaction"
+	OnReactionCount = "\amessage_reaction_count"
+
+	OnGift                = "\agift"
+	OnUniqueGift          = "\aunique_gift"
+	OnChecklist           = "\achecklist"
+	OnChecklistTasksDone  = "\achecklist_tasks_done"
+	OnChecklistTasksAdded = "\achecklist_tasks_added"
+
 	OnBusinessConnection      = "\abusiness_connection"
 	OnBusinessMessage         = "\abusiness_message"
 	OnEditedBusinessMessage   = "\aedited_business_message"
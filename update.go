@@ -1,6 +1,13 @@
 package telebot
 
-import "strings"
+import (
+	"errors"
+	"strings"
+)
+
+// Continue is returned by the handler to pass the update
+// to the next matching endpoint. See ProcessContext.
+var Continue = errors.New("telebot: continue")
 
 // Update object represents an incoming update.
 type Update struct {
@@ -28,6 +35,30 @@ type Update struct {
 	BusinessMessage         *Message                 `json:"business_message"`
 	EditedBusinessMessage   *Message                 `json:"edited_business_message"`
 	DeletedBusinessMessages *BusinessMessagesDeleted `json:"deleted_business_messages"`
+
+	// reply is the webhook response slot, see Webhook.ReplyInResponse.
+	reply *webhookReply
+
+	// meta is the processing state, kept behind
+	// the pointer, so Update stays comparable.
+	meta *updateMeta
+}
+
+type updateMeta struct {
+	// done is called once the update is processed, see LongPoller.OffsetStore.
+	done func()
+}
+
+// onDone sets the function called once the update is processed.
+func (u *Update) onDone(f func()) {
+	u.meta = &updateMeta{done: f}
+}
+
+// done reports the update is processed.
+func (u Update) done() {
+	if u.meta != nil && u.meta.done != nil {
+		u.meta.done()
+	}
 }
 
 // ProcessUpdate processes a single incoming update.
@@ -38,9 +69,34 @@ func (b *Bot) ProcessUpdate(u Update) {
 
 // ProcessContext processes the given context.
 // A started bot calls this function automatically.
+//
+// Every update is passed to the single endpoint matching it, in this
+// order of precedence: the command, the exact text or button unique,
+// the regexp and predicate endpoints in the order of registration, and
+// then the On* event of the update, e.g. the specific media event first
+// and OnMedia after it. A handler may return Continue to pass the update
+// to the next endpoint, as if the handler wasn't registered. OnForward and
+// OnReply only observe the messages, which are dispatched further anyway.
 func (b *Bot) ProcessContext(c Context) {
+	b.runHandler(func(c Context) error {
+		b.dispatch(c)
+		u := c.Update()
+		if u.reply != nil {
+			u.reply.finish()
+		}
+		u.done()
+		return nil
+	}, c)
+}
+
+// dispatch runs the handlers of the endpoints matching the update.
+func (b *Bot) dispatch(c Context) {
 	u := c.Update()
 
+	if u.Message == nil && u.Callback == nil && len(*b.matchers) > 0 && b.handleMatchers(c) {
+		return
+	}
+
 	if u.Message != nil {
 		m := u.Message
 
@@ -80,6 +136,10 @@ func (b *Bot) ProcessContext(c Context) {
 				return
 			}
 
+			if len(*b.matchers) > 0 && b.handleMatchers(c) {
+				return
+			}
+
 			if m.ReplyTo != nil {
 				b.handle(OnReply, c)
 			}
@@ -88,6 +148,10 @@ func (b *Bot) ProcessContext(c Context) {
 			return
 		}
 
+		if len(*b.matchers) > 0 && b.handleMatchers(c) {
+			return
+		}
+
 		if b.handleMedia(c) {
 			return
 		}
@@ -239,6 +303,28 @@ func (b *Bot) ProcessContext(c Context) {
 			return
 		}
 
+		if m.Gift != nil {
+			b.handle(OnGift, c)
+			return
+		}
+		if m.UniqueGift != nil {
+			b.handle(OnUniqueGift, c)
+			return
+		}
+
+		if m.Checklist != nil {
+			b.handle(OnChecklist, c)
+			return
+		}
+		if m.ChecklistTasksDone != nil {
+			b.handle(OnChecklistTasksDone, c)
+			return
+		}
+		if m.ChecklistTasksAdded != nil {
+			b.handle(OnChecklistTasksAdded, c)
+			return
+		}
+
 		if m.ProximityAlert != nil {
 			b.handle(OnProximityAlert, c)
 			return
@@ -271,20 +357,46 @@ func (b *Bot) ProcessContext(c Context) {
 		return
 	}
 
+	if u.MessageReaction != nil {
+		b.handle(OnReaction, c)
+		return
+	}
+	if u.MessageReactionCount != nil {
+		b.handle(OnReactionCount, c)
+		return
+	}
+
 	if u.Callback != nil {
 		if data := u.Callback.Data; data != "" && data[0] == '\f' {
+			if b.signer != nil {
+				verified, err := b.signer.verify(u.Callback)
+				if err != nil {
+					b.OnError(err, c)
+					return
+				}
+				data = verified
+				u.Callback.Data = data
+			}
+
 			match := cbackRx.FindAllStringSubmatch(data, -1)
 			if match != nil {
 				unique, payload := match[0][1], match[0][3]
 				if handler, ok := b.handlers["\f"+unique]; ok {
 					u.Callback.Unique = unique
 					u.Callback.Data = payload
-					b.runHandler(handler, c)
-					return
+					if b.run(handler, c) {
+						return
+					}
+					u.Callback.Unique = ""
+					u.Callback.Data = data
 				}
 			}
 		}
 
+		if len(*b.matchers) > 0 && b.handleMatchers(c) {
+			return
+		}
+
 		b.handle(OnCallback, c)
 		return
 	}
@@ -360,12 +472,24 @@ func (b *Bot) ProcessContext(c Context) {
 
 func (b *Bot) handle(end string, c Context) bool {
 	if handler, ok := b.handlers[end]; ok {
-		b.runHandler(handler, c)
-		return true
+		return b.run(handler, c)
 	}
 	return false
 }
 
+// run runs the handler and reports whether it has handled
+// the update, that is, hasn't returned Continue.
+func (b *Bot) run(h HandlerFunc, c Context) bool {
+	err := h(c)
+	if errors.Is(err, Continue) {
+		return false
+	}
+	if err != nil {
+		b.OnError(err, c)
+	}
+	return true
+}
+
 func (b *Bot) handleMedia(c Context) bool {
 	var (
 		m     = c.Message()
@@ -401,14 +525,19 @@ func (b *Bot) handleMedia(c Context) bool {
 }
 
 func (b *Bot) runHandler(h HandlerFunc, c Context) {
+	b.inflight.Add(1)
 	f := func() {
+		defer b.inflight.Done()
 		if err := h(c); err != nil {
 			b.OnError(err, c)
 		}
 	}
-	if b.synchronous {
+	switch {
+	case b.synchronous:
 		f()
-	} else {
+	case b.pool != nil:
+		b.pool.submit(shardKey(c), f)
+	default:
 		go f()
 	}
 }
@@ -0,0 +1,53 @@
+package telebot
+
+// VerifyUser verifies a user on behalf of the organization which is
+// represented by the bot. The custom description is optional; it's
+// ignored if the organization doesn't allow it.
+func (b *Bot) VerifyUser(user *User, description ...string) error {
+	params := map[string]string{
+		"user_id": user.Recipient(),
+	}
+	if len(description) > 0 {
+		params["custom_description"] = description[0]
+	}
+
+	_, err := b.Raw("verifyUser", params)
+	return err
+}
+
+// VerifyChat verifies a chat on behalf of the organization which is
+// represented by the bot. The custom description is optional; it's
+// ignored if the organization doesn't allow it.
+func (b *Bot) VerifyChat(chat Recipient, description ...string) error {
+	params := map[string]string{
+		"chat_id": chat.Recipient(),
+	}
+	if len(description) > 0 {
+		params["custom_description"] = description[0]
+	}
+
+	_, err := b.Raw("verifyChat", params)
+	return err
+}
+
+// RemoveUserVerification removes verification from a user who is currently
+// verified on behalf of the organization represented by the bot.
+func (b *Bot) RemoveUserVerification(user *User) error {
+	params := map[string]string{
+		"user_id": user.Recipient(),
+	}
+
+	_, err := b.Raw("removeUserVerification", params)
+	return err
+}
+
+// RemoveChatVerification removes verification from a chat that is currently
+// verified on behalf of the organization represented by the bot.
+func (b *Bot) RemoveChatVerification(chat Recipient) error {
+	params := map[string]string{
+		"chat_id": chat.Recipient(),
+	}
+
+	_, err := b.Raw("removeChatVerification", params)
+	return err
+}
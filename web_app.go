@@ -1,5 +1,23 @@
 package telebot
 
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // WebApp represents a parameter of the inline keyboard button
 // or the keyboard button used to launch Web App.
 type WebApp struct {
@@ -24,3 +42,252 @@ type WriteAccessAllowed struct {
 	FromRequest        bool   `json:"from_request,omitempty"`
 	FromAttachmentMenu bool   `json:"from_attachment_menu,omitempty"`
 }
+
+// Telegram's Ed25519 public keys used to sign the Web App init data
+// for the third-party validation.
+var (
+	WebAppPublicKey     = mustHexKey("e7bf03a2fa4602af4580703d88dda5bb59f32ed8b02a56c187fe7d34caed242d")
+	WebAppTestPublicKey = mustHexKey("40055058a4ee38156a06562e52eece92a771bcd8346a8c4615cb7376eddf72ec")
+)
+
+// Web App init data validation errors.
+var (
+	ErrWebAppNoHash      = errors.New("telebot: web app init data is not signed")
+	ErrWebAppBadHash     = errors.New("telebot: web app init data hash mismatch")
+	ErrWebAppBadSign     = errors.New("telebot: web app init data signature mismatch")
+	ErrWebAppDataExpired = errors.New("telebot: web app init data is expired")
+)
+
+// WebAppInitData represents the data transferred to the Web App
+// on its launch, see Telegram.WebApp.initData.
+type WebAppInitData struct {
+	// (Optional) A unique identifier for the Web App session,
+	// required for sending messages via AnswerWebAppQuery.
+	QueryID string `json:"query_id"`
+
+	// (Optional) The user who opened the Web App.
+	User *User `json:"user"`
+
+	// (Optional) The chat partner of the current user in the chat
+	// where the bot was launched via the attachment menu.
+	Receiver *User `json:"receiver"`
+
+	// (Optional) The chat where the bot was launched via the attachment menu.
+	Chat *Chat `json:"chat"`
+
+	// (Optional) Type of the chat from which the Web App was opened.
+	ChatType ChatType `json:"chat_type"`
+
+	// (Optional) Global identifier of the chat from which the Web App was opened.
+	ChatInstance string `json:"chat_instance"`
+
+	// (Optional) The value of the startattach or startapp parameter
+	// passed via link.
+	StartParam string `json:"start_param"`
+
+	// (Optional) Time in seconds, after which a message
+	// can be sent via the AnswerWebAppQuery.
+	CanSendAfter int `json:"can_send_after"`
+
+	// Unixtime, use WebAppInitData.Time() to get time.Time.
+	AuthUnixtime int64 `json:"auth_date"`
+
+	// A hash of all passed parameters, which is used for the validation.
+	Hash string `json:"hash"`
+
+	// (Optional) An Ed25519 signature of all passed parameters,
+	// which is used for the third-party validation.
+	Signature string `json:"signature"`
+}
+
+// Time returns the moment the init data was created.
+func (d *WebAppInitData) Time() time.Time {
+	return time.Unix(d.AuthUnixtime, 0)
+}
+
+// ParseWebAppInitData parses the Web App init data query string
+// WITHOUT validating it. Use ValidateWebAppInitData unless the data
+// comes from a trusted source.
+func ParseWebAppInitData(initData string) (*WebAppInitData, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &WebAppInitData{
+		QueryID:      values.Get("query_id"),
+		ChatType:     ChatType(values.Get("chat_type")),
+		ChatInstance: values.Get("chat_instance"),
+		StartParam:   values.Get("start_param"),
+		Hash:         values.Get("hash"),
+		Signature:    values.Get("signature"),
+	}
+
+	for field, v := range map[string]interface{}{
+		"user":     &d.User,
+		"receiver": &d.Receiver,
+		"chat":     &d.Chat,
+	} {
+		if s := values.Get(field); s != "" {
+			if err := json.Unmarshal([]byte(s), v); err != nil {
+				return nil, fmt.Errorf("telebot: web app init data %s: %w", field, err)
+			}
+		}
+	}
+
+	if s := values.Get("can_send_after"); s != "" {
+		if d.CanSendAfter, err = strconv.Atoi(s); err != nil {
+			return nil, fmt.Errorf("telebot: web app init data can_send_after: %w", err)
+		}
+	}
+	if s := values.Get("auth_date"); s != "" {
+		if d.AuthUnixtime, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("telebot: web app init data auth_date: %w", err)
+		}
+	}
+
+	return d, nil
+}
+
+// ValidateWebAppInitData parses the init data and checks its hash against
+// the bot token. If maxAge is positive, the data created earlier than maxAge
+// ago is rejected with ErrWebAppDataExpired.
+func ValidateWebAppInitData(token, initData string, maxAge time.Duration) (*WebAppInitData, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := hex.DecodeString(values.Get("hash"))
+	if err != nil || len(hash) == 0 {
+		return nil, ErrWebAppNoHash
+	}
+
+	secret := hmacSHA256([]byte("WebAppData"), []byte(token))
+	if !hmac.Equal(hash, hmacSHA256(secret, []byte(dataCheckString(values, "hash")))) {
+		return nil, ErrWebAppBadHash
+	}
+
+	return parseFresh(initData, maxAge)
+}
+
+// ValidateWebAppInitDataThirdParty checks the Ed25519 signature of the init
+// data, which allows validating it without the bot token. Pass WebAppPublicKey
+// or, for the test environment, WebAppTestPublicKey as the key.
+func ValidateWebAppInitDataThirdParty(botID int64, initData string, key ed25519.PublicKey, maxAge time.Duration) (*WebAppInitData, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, err
+	}
+
+	sign, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(values.Get("signature"), "="))
+	if err != nil || len(sign) == 0 {
+		return nil, ErrWebAppNoHash
+	}
+
+	data := strconv.FormatInt(botID, 10) + ":WebAppData\n" + dataCheckString(values, "hash", "signature")
+	if !ed25519.Verify(key, []byte(data), sign) {
+		return nil, ErrWebAppBadSign
+	}
+
+	return parseFresh(initData, maxAge)
+}
+
+func parseFresh(initData string, maxAge time.Duration) (*WebAppInitData, error) {
+	d, err := ParseWebAppInitData(initData)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge > 0 && time.Since(d.Time()) > maxAge {
+		return nil, ErrWebAppDataExpired
+	}
+	return d, nil
+}
+
+// dataCheckString joins the sorted key=value pairs except the given
+// keys with a line feed, as Telegram does for the signing.
+func dataCheckString(values url.Values, except ...string) string {
+	pairs := make([]string, 0, len(values))
+outer:
+	for k := range values {
+		for _, e := range except {
+			if k == e {
+				continue outer
+			}
+		}
+		pairs = append(pairs, k+"="+values.Get(k))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\n")
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func mustHexKey(s string) ed25519.PublicKey {
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+type webAppContextKey struct{}
+
+// WebAppAuth returns an HTTP middleware for the Web App backend, which
+// validates the init data passed in the "Authorization: tma <init data>"
+// header with the bot token. Requests without valid data are answered
+// with 401 Unauthorized. Use WebAppInitDataFrom to access the data:
+//
+//	mux.Handle("/api/", tele.WebAppAuth(token, 24*time.Hour)(api))
+//
+//	func api(w http.ResponseWriter, r *http.Request) {
+//		user := tele.WebAppInitDataFrom(r.Context()).User
+//		...
+//	}
+func WebAppAuth(token string, maxAge time.Duration) func(http.Handler) http.Handler {
+	return webAppAuth(func(initData string) (*WebAppInitData, error) {
+		return ValidateWebAppInitData(token, initData, maxAge)
+	})
+}
+
+// WebAppAuthThirdParty is the same as WebAppAuth, but checks the Ed25519
+// signature of the init data instead of the hash.
+func WebAppAuthThirdParty(botID int64, key ed25519.PublicKey, maxAge time.Duration) func(http.Handler) http.Handler {
+	return webAppAuth(func(initData string) (*WebAppInitData, error) {
+		return ValidateWebAppInitDataThirdParty(botID, initData, key, maxAge)
+	})
+}
+
+func webAppAuth(validate func(string) (*WebAppInitData, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const scheme = "tma "
+
+			auth := r.Header.Get("Authorization")
+			if len(auth) <= len(scheme) || !strings.EqualFold(auth[:len(scheme)], scheme) {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			d, err := validate(auth[len(scheme):])
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), webAppContextKey{}, d)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WebAppInitDataFrom returns the init data validated by
+// the WebAppAuth middleware, or nil if there is no such.
+func WebAppInitDataFrom(ctx context.Context) *WebAppInitData {
+	d, _ := ctx.Value(webAppContextKey{}).(*WebAppInitData)
+	return d
+}
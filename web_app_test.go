@@ -0,0 +1,113 @@
+package telebot
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWebAppInitData(t *testing.T) {
+	const token = "123:secret"
+
+	values := url.Values{
+		"query_id":    {"AAH"},
+		"user":        {`{"id":42,"first_name":"John","language_code":"en"}`},
+		"auth_date":   {strconv.FormatInt(time.Now().Unix(), 10)},
+		"start_param": {"ref"},
+	}
+	secret := hmacSHA256([]byte("WebAppData"), []byte(token))
+	values.Set("hash", hex.EncodeToString(hmacSHA256(secret, []byte(dataCheckString(values)))))
+	initData := values.Encode()
+
+	d, err := ValidateWebAppInitData(token, initData, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "AAH", d.QueryID)
+	assert.Equal(t, int64(42), d.User.ID)
+	assert.Equal(t, "John", d.User.FirstName)
+	assert.Equal(t, "ref", d.StartParam)
+	assert.WithinDuration(t, time.Now(), d.Time(), time.Minute)
+
+	_, err = ValidateWebAppInitData("123:other", initData, time.Hour)
+	assert.Equal(t, ErrWebAppBadHash, err)
+
+	values.Set("start_param", "forged")
+	_, err = ValidateWebAppInitData(token, values.Encode(), time.Hour)
+	assert.Equal(t, ErrWebAppBadHash, err)
+
+	values.Del("hash")
+	_, err = ValidateWebAppInitData(token, values.Encode(), time.Hour)
+	assert.Equal(t, ErrWebAppNoHash, err)
+
+	values = url.Values{"auth_date": {strconv.FormatInt(time.Now().Add(-2*time.Hour).Unix(), 10)}}
+	values.Set("hash", hex.EncodeToString(hmacSHA256(secret, []byte(dataCheckString(values)))))
+	_, err = ValidateWebAppInitData(token, values.Encode(), time.Hour)
+	assert.Equal(t, ErrWebAppDataExpired, err)
+	_, err = ValidateWebAppInitData(token, values.Encode(), 0)
+	assert.NoError(t, err)
+}
+
+func TestValidateWebAppInitDataThirdParty(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	values := url.Values{
+		"user":      {`{"id":42}`},
+		"auth_date": {strconv.FormatInt(time.Now().Unix(), 10)},
+		"hash":      {"ignored"},
+	}
+	sign := ed25519.Sign(priv, []byte("123:WebAppData\n"+dataCheckString(values, "hash")))
+	values.Set("signature", base64.RawURLEncoding.EncodeToString(sign))
+
+	d, err := ValidateWebAppInitDataThirdParty(123, values.Encode(), pub, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), d.User.ID)
+
+	_, err = ValidateWebAppInitDataThirdParty(124, values.Encode(), pub, time.Hour)
+	assert.Equal(t, ErrWebAppBadSign, err)
+
+	_, err = ValidateWebAppInitDataThirdParty(123, values.Encode(), WebAppPublicKey, time.Hour)
+	assert.Equal(t, ErrWebAppBadSign, err)
+}
+
+func TestWebAppAuth(t *testing.T) {
+	const token = "123:secret"
+
+	values := url.Values{
+		"user":      {`{"id":42}`},
+		"auth_date": {strconv.FormatInt(time.Now().Unix(), 10)},
+	}
+	secret := hmacSHA256([]byte("WebAppData"), []byte(token))
+	values.Set("hash", hex.EncodeToString(hmacSHA256(secret, []byte(dataCheckString(values)))))
+
+	h := WebAppAuth(token, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d := WebAppInitDataFrom(r.Context())
+		w.Write([]byte(strconv.FormatInt(d.User.ID, 10)))
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "tma "+values.Encode())
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "42", w.Body.String())
+
+	r.Header.Set("Authorization", "tma "+values.Encode()+"&extra=1")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	r.Header.Del("Authorization")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -1,11 +1,15 @@
 package telebot
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // A WebhookTLS specifies the path to a key and a cert so the poller can open
@@ -59,10 +63,66 @@ type Webhook struct {
 	TLS      *WebhookTLS
 	Endpoint *WebhookEndpoint
 
-	dest chan<- Update
-	bot  *Bot
+	// AllowedSubnets, if set, restricts the requests to the addresses
+	// of these networks, e.g. TelegramSubnets. Others get 403.
+	AllowedSubnets []string `json:"allowed_subnets"`
+
+	// TrustedProxies are the networks of the reverse proxies in front of
+	// the webhook, whose X-Forwarded-For and X-Real-IP headers are trusted
+	// to find the client address.
+	TrustedProxies []string `json:"trusted_proxies"`
+
+	// MaxBodySize limits the size of the request body, defaulted to 1 MiB.
+	// The larger requests get 413.
+	MaxBodySize int64 `json:"max_body_size"`
+
+	// Async acknowledges the updates immediately and enqueues them in
+	// the background, so Telegram doesn't wait for the busy bot.
+	// The updates acknowledged, but not enqueued by the stop, are lost.
+	Async bool `json:"async"`
+
+	// AsyncBuffer is the number of the acknowledged updates waiting to be
+	// enqueued, defaulted to 100. Once it's full, the updates are answered
+	// with 503 Service Unavailable, so Telegram redelivers them later.
+	AsyncBuffer int `json:"async_buffer"`
+
+	// HealthPath and ReadyPath, if set, are the paths of the probes for
+	// the load balancer. The health one is always answered with 200, while
+	// the readiness one only when the webhook is accepting updates, and
+	// with 503 otherwise. The probes bypass the AllowedSubnets.
+	HealthPath string `json:"health_path"`
+	ReadyPath  string `json:"ready_path"`
+
+	// ReplyInResponse makes the webhook wait for the update handler up to
+	// ReplyTimeout, 1 second by default, and put its first Context.Send,
+	// Reply or Respond call into the response instead of making a request.
+	// Telegram doesn't report the result of such call, so the message sent
+	// is empty, and the calls made after it may be executed before it.
+	// The later calls, and those made after the timeout, are requested
+	// as usual.
+	ReplyInResponse bool          `json:"reply_in_response"`
+	ReplyTimeout    time.Duration `json:"reply_timeout"`
+
+	dest    chan<- Update
+	pending chan Update
+	bot     *Bot
+	stop    chan struct{}
+	mu      sync.RWMutex // guards enqueueing against closing
+	closed  int32        // atomic
+	started int32        // atomic
+
+	netsOnce sync.Once
+	allowed  []*net.IPNet
+	proxies  []*net.IPNet
 }
 
+// TelegramSubnets are the networks, which Telegram sends
+// the webhook requests from.
+var TelegramSubnets = []string{"149.154.160.0/20", "91.108.4.0/22"}
+
+// defaultMaxBodySize is the default Webhook.MaxBodySize.
+const defaultMaxBodySize = 1 << 20
+
 func (h *Webhook) getFiles() map[string]File {
 	m := make(map[string]File)
 
@@ -130,9 +190,7 @@ func (h *Webhook) Poll(b *Bot, dest chan Update, stop chan struct{}) {
 		}
 	}
 
-	// store the variables so the HTTP-handler can use 'em
-	h.dest = dest
-	h.bot = b
+	h.attach(b, dest, stop)
 
 	if h.Listen == "" {
 		h.waitForStop(stop)
@@ -144,37 +202,265 @@ func (h *Webhook) Poll(b *Bot, dest chan Update, stop chan struct{}) {
 		Handler: h,
 	}
 
-	go func(stop chan struct{}) {
-		h.waitForStop(stop)
-		s.Shutdown(context.Background())
-	}(stop)
+	b.stopClient.mu.RLock()
+	drained := b.stopClient.drained
+	b.stopClient.mu.RUnlock()
 
-	if h.TLS != nil {
-		s.ListenAndServeTLS(h.TLS.Cert, h.TLS.Key)
-	} else {
-		s.ListenAndServe()
+	b.stopClient.servers.Add(1)
+
+	go func() {
+		var err error
+		if h.TLS != nil {
+			err = s.ListenAndServeTLS(h.TLS.Cert, h.TLS.Key)
+		} else {
+			err = s.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			b.OnError(err, nil)
+		}
+	}()
+
+	h.waitForStop(stop)
+
+	// The server keeps rejecting updates until the handlers are done,
+	// so Telegram redelivers them later instead of dropping.
+	go func() {
+		defer b.stopClient.servers.Done()
+		if drained != nil {
+			<-drained
+		}
+		s.Shutdown(b.stopClient.shutdownContext())
+	}()
+}
+
+// attach stores the variables so the HTTP-handler can use 'em,
+// and starts accepting the updates.
+func (h *Webhook) attach(b *Bot, dest chan<- Update, stop chan struct{}) {
+	h.dest = dest
+	h.bot = b
+	h.stop = stop
+
+	if h.Async {
+		size := h.AsyncBuffer
+		if size <= 0 {
+			size = 100
+		}
+		h.pending = make(chan Update, size)
+		go h.forward(h.pending, dest, stop)
 	}
+
+	atomic.StoreInt32(&h.closed, 0)
+	atomic.StoreInt32(&h.started, 1)
 }
 
+// forward moves the acknowledged updates to the
+// update channel in order, until the stop.
+func (h *Webhook) forward(pending <-chan Update, dest chan<- Update, stop chan struct{}) {
+	for {
+		select {
+		case update := <-pending:
+			select {
+			case dest <- update:
+			case <-stop:
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// waitForStop closes the webhook once the poller is stopped. The updates
+// being enqueued are either enqueued before it, or rejected, so none
+// of them is acknowledged after the Updates channel is drained.
 func (h *Webhook) waitForStop(stop chan struct{}) {
 	<-stop
-	close(stop)
+	h.mu.Lock()
+	atomic.StoreInt32(&h.closed, 1)
+	h.mu.Unlock()
 }
 
-// The handler simply reads the update from the body of the requests
-// and writes them to the update channel.
+// The handler reads the update from the body of the request
+// and writes it to the update channel.
 func (h *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		switch {
+		case h.HealthPath != "" && r.URL.Path == h.HealthPath:
+			w.WriteHeader(http.StatusOK)
+			return
+		case h.ReadyPath != "" && r.URL.Path == h.ReadyPath:
+			if !h.ready() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	if !h.ready() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.netsOnce.Do(h.parseNets)
+	if h.allowed != nil {
+		if ip := h.clientIP(r); ip == nil || !containsIP(h.allowed, ip) {
+			h.bot.debug(fmt.Errorf("webhook request from disallowed address %s", r.RemoteAddr))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
 	if h.SecretToken != "" && r.Header.Get("X-Telegram-Bot-Api-Secret-Token") != h.SecretToken {
 		h.bot.debug(fmt.Errorf("invalid secret token in request"))
+		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
+	limit := h.MaxBodySize
+	if limit <= 0 {
+		limit = defaultMaxBodySize
+	}
+
 	var update Update
-	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit)).Decode(&update); err != nil {
 		h.bot.debug(fmt.Errorf("cannot decode update: %v", err))
+		if err.Error() == "http: request body too large" {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		return
+	}
+
+	if !h.ReplyInResponse {
+		if !h.enqueue(update) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
 		return
 	}
-	h.dest <- update
+
+	timeout := h.ReplyTimeout
+	if timeout <= 0 {
+		timeout = defaultReplyTimeout
+	}
+
+	reply := newWebhookReply()
+	update.reply = reply
+	if !h.enqueue(update) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	if call := reply.wait(timeout); call != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(call)
+	}
+}
+
+// enqueue writes the update to the update channel, unless
+// the webhook is closed. It reports whether the update is taken.
+func (h *Webhook) enqueue(update Update) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if atomic.LoadInt32(&h.closed) == 1 {
+		return false
+	}
+
+	if !h.Async {
+		select {
+		case h.dest <- update:
+			return true
+		case <-h.stop:
+			return false
+		}
+	}
+
+	select {
+	case h.pending <- update:
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *Webhook) ready() bool {
+	return atomic.LoadInt32(&h.started) == 1 && atomic.LoadInt32(&h.closed) == 0
+}
+
+func (h *Webhook) parseNets() {
+	h.allowed = parseNets(h.bot, h.AllowedSubnets)
+	h.proxies = parseNets(h.bot, h.TrustedProxies)
+}
+
+func parseNets(b *Bot, cidrs []string) []*net.IPNet {
+	if len(cidrs) == 0 {
+		return nil
+	}
+
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if strings.Contains(cidr, ":") {
+				cidr += "/128"
+			} else {
+				cidr += "/32"
+			}
+		}
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			b.OnError(fmt.Errorf("telebot: bad webhook subnet: %w", err), nil)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// clientIP returns the address of the client, which is the remote one,
+// unless it's a trusted proxy. The proxies' headers are walked then
+// from the nearest one until the first untrusted address.
+func (h *Webhook) clientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !containsIP(h.proxies, ip) {
+		return ip
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip = net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil || !containsIP(h.proxies, ip) {
+				return ip
+			}
+		}
+		return ip
+	}
+
+	if real := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); real != nil {
+		return real
+	}
+	return ip
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
 // Webhook returns the current webhook status.
@@ -0,0 +1,122 @@
+package telebot
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// defaultReplyTimeout is the default Webhook.ReplyTimeout.
+const defaultReplyTimeout = time.Second
+
+// webhookReplyResult is returned for the call put into the webhook
+// response, since Telegram doesn't report its result.
+var webhookReplyResult = []byte(`{"ok":true,"result":{}}`)
+
+const (
+	replyOpen = iota
+	replyClaimed
+	replyClosed
+)
+
+// webhookReply is the slot of the webhook response, which the first
+// call of the update handler may take instead of making a request.
+type webhookReply struct {
+	mu    sync.Mutex
+	state int
+	call  []byte
+	ready chan struct{}
+}
+
+func newWebhookReply() *webhookReply {
+	return &webhookReply{ready: make(chan struct{})}
+}
+
+// claim puts the call into the response, if the slot is still open.
+func (r *webhookReply) claim(method string, payload interface{}) bool {
+	call, err := webhookReplyBody(method, payload)
+	if err != nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state != replyOpen {
+		return false
+	}
+	r.state = replyClaimed
+	r.call = call
+	close(r.ready)
+	return true
+}
+
+// finish closes the slot once the handler is done.
+func (r *webhookReply) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.close()
+}
+
+// wait waits for the call up to the timeout and closes the slot.
+// It returns the call to put into the response, if any.
+func (r *webhookReply) wait(timeout time.Duration) []byte {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-r.ready:
+	case <-t.C:
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.close()
+	return r.call
+}
+
+func (r *webhookReply) close() {
+	if r.state == replyOpen {
+		r.state = replyClosed
+		close(r.ready)
+	}
+}
+
+// webhookReplyBody returns the payload with the method in it.
+func webhookReplyBody(method string, payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	if body == nil {
+		body = make(map[string]json.RawMessage)
+	}
+
+	body["method"], _ = json.Marshal(method)
+	return json.Marshal(body)
+}
+
+// replyAPI returns the API, whose first call is put into the webhook
+// response of the update, if the webhook replies in the response.
+func (c *nativeContext) replyAPI() API {
+	b, ok := c.b.(*Bot)
+	if !ok || c.u.reply == nil {
+		return c.api()
+	}
+
+	c.lock.RLock()
+	ctx := c.ctx
+	c.lock.RUnlock()
+
+	cp := *b
+	cp.reply = c.u.reply
+	if ctx != nil {
+		cp.ctx = ctx
+	}
+	return boundBot{&cp}
+}
@@ -0,0 +1,179 @@
+package telebot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WebhookRouter serves the webhooks of many bots on a single server.
+// Each webhook is mounted either at its own path, or, if the path is empty,
+// by its secret token. The router is a plain http.Handler, so it may also
+// be embedded into an existing mux instead of listening itself.
+//
+//	wr := &tele.WebhookRouter{
+//		Listen:    ":8443",
+//		PublicURL: "https://bots.example.com",
+//		TLS:       &tele.WebhookTLS{Key: "key.pem", Cert: "cert.pem"},
+//	}
+//
+//	for _, token := range tokens {
+//		wh := &tele.Webhook{SecretToken: secret(token)}
+//		if err := wr.Handle("/"+botID(token), wh); err != nil {
+//			return err
+//		}
+//
+//		b, err := tele.NewBot(tele.Settings{Token: token, Poller: wh})
+//		...
+//		go b.Start()
+//	}
+//
+//	return wr.ListenAndServe()
+type WebhookRouter struct {
+	// Listen is the address of the server started by ListenAndServe.
+	Listen string
+
+	// TLS, if set, is shared by all the webhooks.
+	TLS *WebhookTLS
+
+	// PublicURL is the base URL, which the webhook paths are joined to
+	// for the setWebhook calls. The webhooks routed by the secret token
+	// are registered at the PublicURL itself.
+	PublicURL string
+
+	// Cert is the path to the self-signed certificate uploaded to Telegram.
+	Cert string
+
+	// HealthPath, if set, is answered with 200 for the load balancer.
+	HealthPath string
+
+	mu       sync.RWMutex
+	paths    map[string]*Webhook
+	secrets  map[string]*Webhook
+	server   *http.Server
+	shutdown bool
+}
+
+// Handle mounts the webhook at the path, or routes the requests to it
+// by the secret token, if the path is empty. The webhook must be the poller
+// of the bot and have no Listen address. Its Endpoint, if not set, is built
+// of the PublicURL and the path, so the bot registers it with setWebhook
+// once started.
+func (wr *WebhookRouter) Handle(path string, h *Webhook) error {
+	if h.Listen != "" {
+		return errors.New("telebot: routed webhook must not listen itself")
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		return errors.New("telebot: webhook path must start with a slash")
+	}
+	if path == "" && h.SecretToken == "" {
+		return errors.New("telebot: webhook without a path must have a secret token")
+	}
+
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	if wr.paths == nil {
+		wr.paths = make(map[string]*Webhook)
+		wr.secrets = make(map[string]*Webhook)
+	}
+
+	if path != "" {
+		if _, ok := wr.paths[path]; ok {
+			return errors.New("telebot: webhook path " + path + " is already mounted")
+		}
+		wr.paths[path] = h
+	} else {
+		if _, ok := wr.secrets[h.SecretToken]; ok {
+			return errors.New("telebot: webhook secret token is already mounted")
+		}
+		wr.secrets[h.SecretToken] = h
+	}
+
+	if h.Endpoint == nil && wr.PublicURL != "" {
+		h.Endpoint = &WebhookEndpoint{
+			PublicURL: strings.TrimRight(wr.PublicURL, "/") + path,
+			Cert:      wr.Cert,
+		}
+	}
+	return nil
+}
+
+// Remove unmounts the webhook.
+func (wr *WebhookRouter) Remove(h *Webhook) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	for path, wh := range wr.paths {
+		if wh == h {
+			delete(wr.paths, path)
+		}
+	}
+	for secret, wh := range wr.secrets {
+		if wh == h {
+			delete(wr.secrets, secret)
+		}
+	}
+}
+
+// ServeHTTP passes the request to the webhook mounted at its path,
+// or to the one with its secret token. Others get 404.
+func (wr *WebhookRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if wr.HealthPath != "" && r.URL.Path == wr.HealthPath {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	wr.mu.RLock()
+	h, ok := wr.paths[r.URL.Path]
+	if !ok {
+		h, ok = wr.secrets[r.Header.Get("X-Telegram-Bot-Api-Secret-Token")]
+	}
+	wr.mu.RUnlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+// ListenAndServe starts the server on the Listen address
+// and blocks until it's shut down.
+func (wr *WebhookRouter) ListenAndServe() error {
+	wr.mu.Lock()
+	if wr.shutdown {
+		wr.mu.Unlock()
+		return http.ErrServerClosed
+	}
+	s := &http.Server{Addr: wr.Listen, Handler: wr}
+	wr.server = s
+	wr.mu.Unlock()
+
+	var err error
+	if wr.TLS != nil {
+		err = s.ListenAndServeTLS(wr.TLS.Cert, wr.TLS.Key)
+	} else {
+		err = s.ListenAndServe()
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// Shutdown gracefully shuts the server down. Stop the bots first,
+// so their updates already accepted are handled.
+func (wr *WebhookRouter) Shutdown(ctx context.Context) error {
+	wr.mu.Lock()
+	s := wr.server
+	wr.shutdown = true
+	wr.mu.Unlock()
+
+	if s == nil {
+		return nil
+	}
+	return s.Shutdown(ctx)
+}
@@ -0,0 +1,63 @@
+package telebot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRouter(t *testing.T) {
+	wr := &WebhookRouter{
+		PublicURL:  "https://bots.example.com/",
+		Cert:       "cert.pem",
+		HealthPath: "/healthz",
+	}
+
+	first := &Webhook{SecretToken: "first"}
+	second := &Webhook{SecretToken: "second"}
+	third := &Webhook{SecretToken: "third", Endpoint: &WebhookEndpoint{PublicURL: "https://other.example.com"}}
+
+	require.NoError(t, wr.Handle("/first", first))
+	require.NoError(t, wr.Handle("/second", second))
+	require.NoError(t, wr.Handle("", third))
+
+	assert.Error(t, wr.Handle("/first", &Webhook{}))
+	assert.Error(t, wr.Handle("", &Webhook{SecretToken: "third"}))
+	assert.Error(t, wr.Handle("", &Webhook{}))
+	assert.Error(t, wr.Handle("relative", &Webhook{}))
+	assert.Error(t, wr.Handle("/listen", &Webhook{Listen: ":8443"}))
+
+	assert.Equal(t, "https://bots.example.com/first", first.getParams()["url"])
+	assert.Equal(t, FromDisk("cert.pem"), first.getFiles()["certificate"])
+	assert.Equal(t, "https://other.example.com", third.getParams()["url"])
+
+	firstDest := newTestWebhook(t, first, 1)
+	secondDest := newTestWebhook(t, second, 1)
+	thirdDest := newTestWebhook(t, third, 1)
+
+	serve := func(path, secret, body string) int {
+		r := httptest.NewRequest("POST", path, strings.NewReader(body))
+		r.Header.Set("X-Telegram-Bot-Api-Secret-Token", secret)
+		w := httptest.NewRecorder()
+		wr.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/first", "first", `{"update_id":1}`))
+	assert.Equal(t, http.StatusOK, serve("/second", "second", `{"update_id":2}`))
+	assert.Equal(t, http.StatusOK, serve("/", "third", `{"update_id":3}`))
+	assert.Equal(t, http.StatusUnauthorized, serve("/second", "first", `{"update_id":4}`))
+	assert.Equal(t, http.StatusNotFound, serve("/unknown", "unknown", `{}`))
+	assert.Equal(t, http.StatusOK, serve("/healthz", "", ""))
+
+	assert.Equal(t, 1, (<-firstDest).ID)
+	assert.Equal(t, 2, (<-secondDest).ID)
+	assert.Equal(t, 3, (<-thirdDest).ID)
+
+	wr.Remove(first)
+	assert.Equal(t, http.StatusNotFound, serve("/first", "first", `{"update_id":5}`))
+}
@@ -0,0 +1,227 @@
+package telebot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWebhook(t *testing.T, h *Webhook, capacity int) chan Update {
+	b, err := NewBot(Settings{Offline: true})
+	require.NoError(t, err)
+
+	dest := make(chan Update, capacity)
+	h.attach(b, dest, make(chan struct{}))
+	return dest
+}
+
+func serveWebhook(h *Webhook, method, path, body string, header http.Header) int {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.RemoteAddr = "149.154.167.1:443"
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestWebhookServeHTTP(t *testing.T) {
+	h := &Webhook{
+		SecretToken: "secret",
+		MaxBodySize: 64,
+		HealthPath:  "/healthz",
+		ReadyPath:   "/readyz",
+	}
+
+	// not started yet
+	assert.Equal(t, http.StatusServiceUnavailable, serveWebhook(h, "POST", "/", `{}`, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, serveWebhook(h, "GET", "/readyz", "", nil))
+	assert.Equal(t, http.StatusOK, serveWebhook(h, "GET", "/healthz", "", nil))
+
+	dest := newTestWebhook(t, h, 1)
+	assert.Equal(t, http.StatusOK, serveWebhook(h, "GET", "/readyz", "", nil))
+
+	secret := http.Header{"X-Telegram-Bot-Api-Secret-Token": {"secret"}}
+	assert.Equal(t, http.StatusMethodNotAllowed, serveWebhook(h, "GET", "/", "", secret))
+	assert.Equal(t, http.StatusUnauthorized, serveWebhook(h, "POST", "/", `{"update_id":1}`, nil))
+	assert.Equal(t, http.StatusBadRequest, serveWebhook(h, "POST", "/", `{`, secret))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serveWebhook(h, "POST", "/",
+		`{"update_id":1,"message":{"text":"`+strings.Repeat("x", 64)+`"}}`, secret))
+
+	assert.Equal(t, http.StatusOK, serveWebhook(h, "POST", "/", `{"update_id":1}`, secret))
+	assert.Equal(t, 1, (<-dest).ID)
+
+	// the blocked update is rejected once the webhook is stopped
+	dest <- Update{ID: 3}
+	done := make(chan int)
+	go func() {
+		done <- serveWebhook(h, "POST", "/", `{"update_id":2}`, secret)
+	}()
+	close(h.stop)
+	h.waitForStop(h.stop)
+	assert.Equal(t, http.StatusServiceUnavailable, <-done)
+	assert.Equal(t, 3, (<-dest).ID)
+
+	assert.Equal(t, http.StatusServiceUnavailable, serveWebhook(h, "POST", "/", `{"update_id":2}`, secret))
+	assert.Equal(t, http.StatusServiceUnavailable, serveWebhook(h, "GET", "/readyz", "", nil))
+}
+
+func TestWebhookAllowedSubnets(t *testing.T) {
+	h := &Webhook{
+		AllowedSubnets: TelegramSubnets,
+		TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"},
+		HealthPath:     "/healthz",
+	}
+	dest := newTestWebhook(t, h, 10)
+
+	serve := func(remote string, header http.Header) int {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+		r.RemoteAddr = remote
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("149.154.167.1:443", nil))
+	assert.Equal(t, http.StatusOK, serve("91.108.6.1:443", nil))
+	assert.Equal(t, http.StatusForbidden, serve("1.2.3.4:443", nil))
+
+	// untrusted proxy headers are ignored
+	assert.Equal(t, http.StatusForbidden, serve("1.2.3.4:443", http.Header{"X-Forwarded-For": {"149.154.167.1"}}))
+
+	// trusted proxy chain
+	assert.Equal(t, http.StatusOK, serve("10.1.1.1:80", http.Header{"X-Forwarded-For": {"149.154.167.1, 192.168.1.1"}}))
+	assert.Equal(t, http.StatusForbidden, serve("10.1.1.1:80", http.Header{"X-Forwarded-For": {"149.154.167.1, 1.2.3.4"}}))
+	assert.Equal(t, http.StatusOK, serve("10.1.1.1:80", http.Header{"X-Real-Ip": {"91.108.4.10"}}))
+	assert.Equal(t, http.StatusForbidden, serve("10.1.1.1:80", nil))
+
+	// probes bypass the allowlist
+	r := httptest.NewRequest("GET", "/healthz", nil)
+	r.RemoteAddr = "10.1.1.1:80"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Len(t, dest, 4)
+}
+
+func TestWebhookAsync(t *testing.T) {
+	h := &Webhook{Async: true, AsyncBuffer: 2}
+	dest := newTestWebhook(t, h, 0)
+
+	done := make(chan int)
+	go func() {
+		done <- serveWebhook(h, "POST", "/", `{"update_id":1}`, nil)
+	}()
+
+	select {
+	case code := <-done:
+		assert.Equal(t, http.StatusOK, code)
+	case <-time.After(time.Second):
+		t.Fatal("webhook is blocked by the full queue")
+	}
+	assert.Equal(t, 1, (<-dest).ID)
+
+	// the buffer is bounded, the update being forwarded aside
+	id := 2
+	for ; id < 10; id++ {
+		code := serveWebhook(h, "POST", "/", `{"update_id":`+strconv.Itoa(id)+`}`, nil)
+		if code != http.StatusOK {
+			assert.Equal(t, http.StatusServiceUnavailable, code)
+			break
+		}
+	}
+	require.LessOrEqual(t, id, 5)
+
+	// in order
+	for i := 2; i < id; i++ {
+		assert.Equal(t, i, (<-dest).ID)
+	}
+
+	// pending updates are dropped by the stop
+	assert.Equal(t, http.StatusOK, serveWebhook(h, "POST", "/", `{"update_id":10}`, nil))
+	close(h.stop)
+}
+
+func TestWebhookReplyInResponse(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	requested := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:])
+		mu.Unlock()
+		w.Write([]byte(`{"ok":true,"result":{"message_id":2,"chat":{"id":1}}}`))
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true, Synchronous: true})
+	require.NoError(t, err)
+
+	h := &Webhook{ReplyInResponse: true, ReplyTimeout: 100 * time.Millisecond}
+	dest := make(chan Update)
+	h.attach(b, dest, make(chan struct{}))
+
+	go func() {
+		for u := range dest {
+			b.ProcessUpdate(u)
+		}
+	}()
+	defer close(dest)
+
+	b.Handle("/start", func(c Context) error {
+		require.NoError(t, c.Send("Hello!"))
+		return c.Send("Second")
+	})
+	b.Handle("/slow", func(c Context) error {
+		time.Sleep(200 * time.Millisecond)
+		return c.Send("Late")
+	})
+	b.Handle(OnText, func(c Context) error {
+		return nil
+	})
+
+	serve := func(text string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(
+			`{"update_id":1,"message":{"text":"`+text+`","chat":{"id":1}}}`))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/start")
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"method":"sendMessage","chat_id":"1","text":"Hello!"}`, w.Body.String())
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []string{"sendMessage"}, requested())
+
+	// the handler is done without calls
+	start := time.Now()
+	w = serve("hello")
+	assert.Empty(t, w.Body.String())
+	assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+
+	// the deadline is exceeded
+	w = serve("/slow")
+	assert.Empty(t, w.Body.String())
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, []string{"sendMessage", "sendMessage"}, requested())
+}
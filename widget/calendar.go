@@ -0,0 +1,342 @@
+package widget
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+	"gopkg.in/telebot.v4/layout"
+)
+
+// CalendarLocale names the months and weekdays of the calendar.
+type CalendarLocale struct {
+	// Months starting with January.
+	Months [12]string
+
+	// Weekdays starting with Monday.
+	Weekdays [7]string
+}
+
+// CalendarEnglish is the default calendar locale.
+var CalendarEnglish = CalendarLocale{
+	Months: [12]string{
+		"January", "February", "March", "April", "May", "June", "July",
+		"August", "September", "October", "November", "December",
+	},
+	Weekdays: [7]string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"},
+}
+
+// LayoutLocale returns the calendar locale of the context taken from the
+// layout texts. The months and weekdays are space-separated, e.g.
+//
+//	calendar:
+//	  months: Січень Лютий Березень Квітень Травень Червень Липень Серпень Вересень Жовтень Листопад Грудень
+//	  weekdays: Пн Вт Ср Чт Пт Сб Нд
+//
+// CalendarEnglish is used for the contexts without a locale,
+// and instead of the texts missing or malformed in the locale.
+func LayoutLocale(lt *layout.Layout) func(tele.Context) CalendarLocale {
+	return func(c tele.Context) CalendarLocale {
+		loc := CalendarEnglish
+
+		locale, ok := lt.Locale(c)
+		if !ok {
+			return loc
+		}
+
+		text := func(k string) string {
+			if !lt.HasTextLocale(locale, k) {
+				return ""
+			}
+			return lt.TextLocale(locale, k)
+		}
+
+		if months := strings.Fields(text("calendar.months")); len(months) == 12 {
+			copy(loc.Months[:], months)
+		}
+		if weekdays := strings.Fields(text("calendar.weekdays")); len(weekdays) == 7 {
+			copy(loc.Weekdays[:], weekdays)
+		}
+		return loc
+	}
+}
+
+// Calendar is a date picker navigated by months, which may also
+// pick the time of the day. The picked time is passed to OnPick.
+//
+//	cal := widget.NewCalendar(b, "meeting", func(c tele.Context, t time.Time) error {
+//		return c.Edit("The meeting is scheduled at " + t.Format(time.RFC1123))
+//	})
+//	cal.Time = true
+//	cal.Min = time.Now()
+//
+//	b.Handle("/schedule", func(c tele.Context) error {
+//		return cal.Send(c, "When?")
+//	})
+type Calendar struct {
+	// Min and Max bound the time to pick, if set.
+	Min, Max time.Time
+
+	// Location of the picked time, defaulted to UTC.
+	Location *time.Location
+
+	// Locale returns the names of the months and weekdays,
+	// defaulted to CalendarEnglish.
+	Locale func(tele.Context) CalendarLocale
+
+	// SundayFirst starts the weeks with Sunday instead of Monday.
+	SundayFirst bool
+
+	// Time enables the selection of the hour and minutes once the date
+	// is picked. The minutes are offered with MinuteStep, 15 by default.
+	Time       bool
+	MinuteStep int
+
+	// OnPick is called with the picked time. The callback
+	// is already answered by then.
+	OnPick func(c tele.Context, t time.Time) error
+
+	unique string
+}
+
+const (
+	calendarMonth  = "2006-01"
+	calendarDay    = "2006-01-02"
+	calendarHour   = "2006-01-02T15"
+	calendarMinute = "2006-01-02T15:04"
+	calendarNoop   = "-"
+)
+
+// NewCalendar returns a new calendar and registers
+// the handler of its buttons with the unique.
+func NewCalendar(r Router, unique string, onPick func(tele.Context, time.Time) error) *Calendar {
+	cal := &Calendar{
+		Location:   time.UTC,
+		MinuteStep: 15,
+		OnPick:     onPick,
+		unique:     unique,
+	}
+	r.Handle(&tele.Btn{Unique: unique}, cal.onClick)
+	return cal
+}
+
+// Send sends the message with the calendar of the current month,
+// or the closest one within the bounds.
+func (cal *Calendar) Send(c tele.Context, what interface{}, opts ...interface{}) error {
+	month := time.Now().In(cal.Location)
+	if !cal.Min.IsZero() && month.Before(cal.Min) {
+		month = cal.Min.In(cal.Location)
+	}
+	if !cal.Max.IsZero() && month.After(cal.Max) {
+		month = cal.Max.In(cal.Location)
+	}
+	return c.Send(what, append(opts, cal.Markup(c, month))...)
+}
+
+// Markup returns the keyboard with the days of the month.
+func (cal *Calendar) Markup(c tele.Context, month time.Time) *tele.ReplyMarkup {
+	loc := cal.locale(c)
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, cal.Location)
+	prev, next := first.AddDate(0, -1, 0), first.AddDate(0, 1, 0)
+
+	header := tele.Row{cal.noop(" ")}
+	if cal.Min.IsZero() || first.After(cal.Min) {
+		header[0] = cal.btn("‹", prev.Format(calendarMonth))
+	}
+	header = append(header, cal.noop(fmt.Sprintf("%s %d", loc.Months[first.Month()-1], first.Year())))
+	if cal.Max.IsZero() || !next.After(cal.Max) {
+		header = append(header, cal.btn("›", next.Format(calendarMonth)))
+	} else {
+		header = append(header, cal.noop(" "))
+	}
+
+	weekdays := make(tele.Row, 7)
+	for i := range weekdays {
+		weekdays[i] = cal.noop(loc.Weekdays[(i+cal.weekStart())%7])
+	}
+
+	kb := []tele.Row{header, weekdays}
+
+	// Column of the first day of the month.
+	offset := (int(first.Weekday()) + 6 - cal.weekStart()) % 7
+	week := make(tele.Row, 0, 7)
+	for i := 0; i < offset; i++ {
+		week = append(week, cal.noop(" "))
+	}
+
+	for day := first; day.Before(next); day = day.AddDate(0, 0, 1) {
+		text := strconv.Itoa(day.Day())
+		if cal.allowed(day, day.AddDate(0, 0, 1)) {
+			week = append(week, cal.btn(text, day.Format(calendarDay)))
+		} else {
+			week = append(week, cal.noop("·"))
+		}
+
+		if len(week) == 7 {
+			kb = append(kb, week)
+			week = make(tele.Row, 0, 7)
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, cal.noop(" "))
+		}
+		kb = append(kb, week)
+	}
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(kb...)
+	return markup
+}
+
+// hoursMarkup returns the keyboard with the hours of the day.
+func (cal *Calendar) hoursMarkup(c tele.Context, day time.Time) *tele.ReplyMarkup {
+	var btns []tele.Btn
+	for h := 0; h < 24; h++ {
+		start := time.Date(day.Year(), day.Month(), day.Day(), h, 0, 0, 0, cal.Location)
+		text := fmt.Sprintf("%02d", h)
+		if cal.allowed(start, start.Add(time.Hour)) {
+			btns = append(btns, cal.btn(text, start.Format(calendarHour)))
+		} else {
+			btns = append(btns, cal.noop("·"))
+		}
+	}
+
+	kb := []tele.Row{{cal.noop(cal.dayTitle(c, day))}}
+	kb = append(kb, rows(btns, 6)...)
+	kb = append(kb, tele.Row{cal.btn("‹", day.Format(calendarMonth))})
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(kb...)
+	return markup
+}
+
+// minutesMarkup returns the keyboard with the minutes of the hour.
+func (cal *Calendar) minutesMarkup(c tele.Context, hour time.Time) *tele.ReplyMarkup {
+	step := cal.MinuteStep
+	if step <= 0 || step > 60 {
+		step = 15
+	}
+
+	var btns []tele.Btn
+	for m := 0; m < 60; m += step {
+		t := hour.Add(time.Duration(m) * time.Minute)
+		text := t.Format("15:04")
+		if cal.allowed(t, t.Add(time.Nanosecond)) {
+			btns = append(btns, cal.btn(text, t.Format(calendarMinute)))
+		} else {
+			btns = append(btns, cal.noop("·"))
+		}
+	}
+
+	kb := []tele.Row{{cal.noop(cal.dayTitle(c, hour))}}
+	kb = append(kb, rows(btns, 4)...)
+	kb = append(kb, tele.Row{cal.btn("‹", hour.Format(calendarDay))})
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(kb...)
+	return markup
+}
+
+func (cal *Calendar) onClick(c tele.Context) error {
+	data := c.Data()
+	if data == calendarNoop || len(data) < 2 {
+		return c.Respond()
+	}
+
+	kind, value := data[0], data[1:]
+
+	var (
+		layout string
+		pick   bool
+	)
+	switch kind {
+	case 'm':
+		layout = calendarMonth
+	case 'd':
+		layout, pick = calendarDay, !cal.Time
+	case 'h':
+		layout = calendarHour
+	case 't':
+		layout, pick = calendarMinute, true
+	default:
+		return c.Respond()
+	}
+
+	t, err := time.ParseInLocation(layout, value, cal.Location)
+	if err != nil {
+		return c.Respond()
+	}
+
+	if pick {
+		if !cal.allowed(t, t.Add(time.Nanosecond)) {
+			return c.Respond()
+		}
+		if err := c.Respond(); err != nil {
+			return err
+		}
+		return cal.OnPick(c, t)
+	}
+
+	var markup *tele.ReplyMarkup
+	switch kind {
+	case 'm':
+		markup = cal.Markup(c, t)
+	case 'd':
+		markup = cal.hoursMarkup(c, t)
+	case 'h':
+		markup = cal.minutesMarkup(c, t)
+	}
+
+	return editMarkup(c, markup)
+}
+
+// allowed reports whether the [start, end) period intersects the bounds.
+func (cal *Calendar) allowed(start, end time.Time) bool {
+	if !cal.Min.IsZero() && !end.After(cal.Min) {
+		return false
+	}
+	if !cal.Max.IsZero() && start.After(cal.Max) {
+		return false
+	}
+	return true
+}
+
+func (cal *Calendar) weekStart() int {
+	if cal.SundayFirst {
+		return 6
+	}
+	return 0
+}
+
+func (cal *Calendar) locale(c tele.Context) CalendarLocale {
+	if cal.Locale == nil {
+		return CalendarEnglish
+	}
+	return cal.Locale(c)
+}
+
+func (cal *Calendar) dayTitle(c tele.Context, day time.Time) string {
+	return fmt.Sprintf("%d %s %d", day.Day(), cal.locale(c).Months[day.Month()-1], day.Year())
+}
+
+func (cal *Calendar) btn(text, data string) tele.Btn {
+	var kind string
+	switch len(data) {
+	case len(calendarMonth):
+		kind = "m"
+	case len(calendarDay):
+		kind = "d"
+	case len(calendarHour):
+		kind = "h"
+	default:
+		kind = "t"
+	}
+	return tele.Btn{Text: text, Unique: cal.unique, Data: kind + data}
+}
+
+func (cal *Calendar) noop(text string) tele.Btn {
+	return tele.Btn{Text: text, Unique: cal.unique, Data: calendarNoop}
+}
@@ -0,0 +1,145 @@
+package widget
+
+import (
+	"strconv"
+	"strings"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// MenuItem is a node of the menu tree.
+type MenuItem struct {
+	// Text is the text of the item's button and breadcrumb.
+	Text string
+
+	// Message is shown under the breadcrumbs when the item is opened.
+	Message string
+
+	// Items are the submenu of the item.
+	Items []*MenuItem
+
+	// Btn, if set, is used as the item's button instead of
+	// opening the submenu, e.g. a button with its own handler.
+	Btn *tele.Btn
+}
+
+// Menu is a tree of the nested keyboards navigated by editing the message.
+// The opened item's path is shown as breadcrumbs with the back button
+// leading to the parent item.
+//
+//	menu := widget.NewMenu(b, "settings", &widget.MenuItem{
+//		Text: "Settings",
+//		Items: []*widget.MenuItem{
+//			{Text: "Language", Items: []*widget.MenuItem{
+//				{Btn: &btnEnglish},
+//				{Btn: &btnUkrainian},
+//			}},
+//			{Text: "Notifications", Message: "Choose the notifications", Items: ...},
+//		},
+//	})
+//
+//	b.Handle("/settings", menu.Send)
+type Menu struct {
+	// Columns is the number of items in a row, defaulted to 1.
+	Columns int
+
+	// Labels of the navigation buttons. The home button
+	// is shown starting from the second level.
+	Back, Home string
+
+	// Separator joins the breadcrumbs.
+	Separator string
+
+	unique string
+	root   *MenuItem
+}
+
+// NewMenu returns a new menu and registers the handler
+// of its navigation buttons with the unique.
+func NewMenu(r Router, unique string, root *MenuItem) *Menu {
+	m := &Menu{
+		Columns:   1,
+		Back:      "‹ Back",
+		Home:      "« Home",
+		Separator: " › ",
+		unique:    unique,
+		root:      root,
+	}
+	r.Handle(&tele.Btn{Unique: unique}, m.onOpen)
+	return m
+}
+
+// Send sends the message with the root of the menu.
+func (m *Menu) Send(c tele.Context) error {
+	text, markup := m.Render(nil)
+	return c.Send(text, markup)
+}
+
+// Render returns the text and keyboard of the item found by the path
+// of indices, or of the root if the path is invalid.
+func (m *Menu) Render(path []int) (string, *tele.ReplyMarkup) {
+	item := m.root
+	crumbs := []string{item.Text}
+	for i, idx := range path {
+		if idx < 0 || idx >= len(item.Items) {
+			path = path[:i]
+			break
+		}
+		item = item.Items[idx]
+		crumbs = append(crumbs, item.Text)
+	}
+
+	text := strings.Join(crumbs, m.Separator)
+	if item.Message != "" {
+		text += "\n\n" + item.Message
+	}
+
+	btns := make([]tele.Btn, len(item.Items))
+	for i, child := range item.Items {
+		if child.Btn != nil {
+			btns[i] = *child.Btn
+		} else {
+			btns[i] = m.btn(child.Text, append(path[:len(path):len(path)], i))
+		}
+	}
+
+	kb := rows(btns, m.Columns)
+	if len(path) > 0 {
+		nav := tele.Row{m.btn(m.Back, path[:len(path)-1])}
+		if len(path) > 1 {
+			nav = append(nav, m.btn(m.Home, nil))
+		}
+		kb = append(kb, nav)
+	}
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(kb...)
+	return text, markup
+}
+
+func (m *Menu) btn(text string, path []int) tele.Btn {
+	parts := make([]string, len(path))
+	for i, idx := range path {
+		parts[i] = strconv.Itoa(idx)
+	}
+	return tele.Btn{Text: text, Unique: m.unique, Data: strings.Join(parts, ".")}
+}
+
+func (m *Menu) onOpen(c tele.Context) error {
+	var path []int
+	if data := c.Data(); data != "" {
+		for _, s := range strings.Split(data, ".") {
+			idx, err := strconv.Atoi(s)
+			if err != nil {
+				return c.Respond()
+			}
+			path = append(path, idx)
+		}
+	}
+
+	text, markup := m.Render(path)
+	if err := c.Edit(text, markup); err != nil && !notModified(err) {
+		return err
+	}
+	return c.Respond()
+}
@@ -0,0 +1,163 @@
+package widget
+
+import (
+	"fmt"
+	"strconv"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// ItemsFunc returns the buttons of the items starting with the offset,
+// no more than limit, and the total number of items.
+type ItemsFunc func(c tele.Context, offset, limit int) (items []tele.Btn, total int, err error)
+
+// SliceItems returns the ItemsFunc serving the static list of buttons.
+func SliceItems(btns []tele.Btn) ItemsFunc {
+	return func(_ tele.Context, offset, limit int) ([]tele.Btn, int, error) {
+		if offset > len(btns) {
+			offset = len(btns)
+		}
+		end := offset + limit
+		if end > len(btns) {
+			end = len(btns)
+		}
+		return btns[offset:end], len(btns), nil
+	}
+}
+
+// Paginator is a keyboard of the list split into pages, navigated with
+// the first, previous, next and last page buttons. The items are usual
+// buttons with their own handlers.
+//
+//	p := widget.NewPaginator(b, "products", func(c tele.Context, offset, limit int) ([]tele.Btn, int, error) {
+//		products, total, err := db.Products(offset, limit)
+//		...
+//	})
+//
+//	b.Handle("/products", func(c tele.Context) error {
+//		return p.Send(c, "Our products:")
+//	})
+type Paginator struct {
+	// PageSize is the number of items on a page, defaulted to 5.
+	PageSize int
+
+	// Columns is the number of items in a row, defaulted to 1.
+	Columns int
+
+	// Labels of the navigation buttons.
+	First, Prev, Next, Last string
+
+	// Indicator is a format of the current page indicator,
+	// which gets the page number and the number of pages.
+	Indicator string
+
+	unique string
+	items  ItemsFunc
+}
+
+// NewPaginator returns a new paginator and registers
+// the handler of its navigation buttons with the unique.
+func NewPaginator(r Router, unique string, items ItemsFunc) *Paginator {
+	p := &Paginator{
+		PageSize:  5,
+		Columns:   1,
+		First:     "«",
+		Prev:      "‹",
+		Next:      "›",
+		Last:      "»",
+		Indicator: "%d/%d",
+		unique:    unique,
+		items:     items,
+	}
+	r.Handle(&tele.Btn{Unique: unique}, p.onPage)
+	return p
+}
+
+// Send sends the message with the first page of the keyboard.
+func (p *Paginator) Send(c tele.Context, what interface{}, opts ...interface{}) error {
+	markup, err := p.Markup(c, 0)
+	if err != nil {
+		return err
+	}
+	return c.Send(what, append(opts, markup)...)
+}
+
+// Markup returns the keyboard of the page, counting from zero.
+// The pages out of range are clamped.
+func (p *Paginator) Markup(c tele.Context, page int) (*tele.ReplyMarkup, error) {
+	if page < 0 {
+		page = 0
+	}
+
+	size := p.PageSize
+	if size < 1 {
+		size = 5
+	}
+
+	items, total, err := p.items(c, page*size, size)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := (total + size - 1) / size
+	if pages < 1 {
+		pages = 1
+	}
+	if page >= pages {
+		page = pages - 1
+		if items, total, err = p.items(c, page*size, size); err != nil {
+			return nil, err
+		}
+	}
+
+	markup := &tele.ReplyMarkup{}
+	kb := rows(items, p.Columns)
+	if pages > 1 {
+		kb = append(kb, p.navigation(page, pages))
+	}
+	markup.Inline(kb...)
+	return markup, nil
+}
+
+func (p *Paginator) navigation(page, pages int) tele.Row {
+	btn := func(text string, page int) tele.Btn {
+		return tele.Btn{Text: text, Unique: p.unique, Data: strconv.Itoa(page)}
+	}
+
+	var row tele.Row
+	if page > 1 {
+		row = append(row, btn(p.First, 0))
+	}
+	if page > 0 {
+		row = append(row, btn(p.Prev, page-1))
+	}
+
+	// The indicator does nothing, but still has to be a callback button.
+	row = append(row, tele.Btn{
+		Text:   fmt.Sprintf(p.Indicator, page+1, pages),
+		Unique: p.unique,
+		Data:   "-",
+	})
+
+	if page < pages-1 {
+		row = append(row, btn(p.Next, page+1))
+	}
+	if page < pages-2 {
+		row = append(row, btn(p.Last, pages-1))
+	}
+	return row
+}
+
+func (p *Paginator) onPage(c tele.Context) error {
+	page, err := strconv.Atoi(c.Data())
+	if err != nil {
+		return c.Respond()
+	}
+
+	markup, err := p.Markup(c, page)
+	if err != nil {
+		return err
+	}
+
+	return editMarkup(c, markup)
+}
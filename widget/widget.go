@@ -0,0 +1,48 @@
+// Package widget provides the interactive inline keyboards,
+// which handle their own callbacks: paginated lists, menus and calendars.
+package widget
+
+import (
+	"errors"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// Router is implemented by both tele.Bot and tele.Group.
+type Router interface {
+	Handle(endpoint interface{}, h tele.HandlerFunc, m ...tele.MiddlewareFunc)
+}
+
+// notModified reports whether the error is caused by editing
+// the message without any changes, which is fine for widgets.
+func notModified(err error) bool {
+	return errors.Is(err, tele.ErrSameMessageContent) || errors.Is(err, tele.ErrMessageNotModified)
+}
+
+// editMarkup replaces the keyboard of the callback message within
+// the deadline of the context, and answers the callback.
+func editMarkup(c tele.Context, markup *tele.ReplyMarkup) error {
+	_, err := c.Bot().WithContext(c.StdContext()).EditReplyMarkup(c.Callback(), markup)
+	if err != nil && !notModified(err) {
+		return err
+	}
+	return c.Respond()
+}
+
+// rows splits the buttons into rows of the given number of columns.
+func rows(btns []tele.Btn, columns int) []tele.Row {
+	if columns < 1 {
+		columns = 1
+	}
+
+	var rows []tele.Row
+	for len(btns) > 0 {
+		n := columns
+		if n > len(btns) {
+			n = len(btns)
+		}
+		rows = append(rows, tele.Row(btns[:n]))
+		btns = btns[n:]
+	}
+	return rows
+}
@@ -0,0 +1,264 @@
+package widget
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v4"
+	"gopkg.in/telebot.v4/layout"
+)
+
+// newTestBot returns the bot sending the requests to the test server,
+// which records the last request of every method.
+func newTestBot(t *testing.T) (*tele.Bot, map[string]map[string]string) {
+	requests := make(map[string]map[string]string)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]string
+		json.NewDecoder(r.Body).Decode(&params)
+		requests[r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]] = params
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1,"chat":{"id":1}}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	b, err := tele.NewBot(tele.Settings{URL: srv.URL, Offline: true, Synchronous: true})
+	require.NoError(t, err)
+	return b, requests
+}
+
+func buttonTexts(markup *tele.ReplyMarkup) [][]string {
+	var texts [][]string
+	for _, row := range markup.InlineKeyboard {
+		var r []string
+		for _, btn := range row {
+			r = append(r, btn.Text)
+		}
+		texts = append(texts, r)
+	}
+	return texts
+}
+
+func TestPaginator(t *testing.T) {
+	b, requests := newTestBot(t)
+
+	var items []tele.Btn
+	for i := 1; i <= 12; i++ {
+		items = append(items, tele.Btn{Text: strconv.Itoa(i), Unique: "item", Data: strconv.Itoa(i)})
+	}
+
+	p := NewPaginator(b, "list", SliceItems(items))
+	p.PageSize = 3
+	p.Columns = 2
+
+	markup, err := p.Markup(nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"1", "2"}, {"3"}, {"1/4", "›", "»"}}, buttonTexts(markup))
+
+	markup, err = p.Markup(nil, 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"7", "8"}, {"9"}, {"«", "‹", "3/4", "›"}}, buttonTexts(markup))
+
+	// clamped
+	markup, err = p.Markup(nil, 10)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"10", "11"}, {"12"}, {"«", "‹", "4/4"}}, buttonTexts(markup))
+
+	next := markup.InlineKeyboard[2][1]
+	assert.Equal(t, "list", next.Unique)
+	assert.Equal(t, "2", next.Data)
+
+	b.ProcessUpdate(tele.Update{Callback: &tele.Callback{
+		ID:      "1",
+		Data:    "\flist|1",
+		Message: &tele.Message{ID: 1, Chat: &tele.Chat{ID: 1}},
+	}})
+
+	var edited tele.ReplyMarkup
+	require.NoError(t, json.Unmarshal([]byte(requests["editMessageReplyMarkup"]["reply_markup"]), &edited))
+	assert.Equal(t, [][]string{{"4", "5"}, {"6"}, {"‹", "2/4", "›", "»"}}, buttonTexts(&edited))
+	assert.Contains(t, requests, "answerCallbackQuery")
+
+	// the edit is bound to the context
+	delete(requests, "editMessageReplyMarkup")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := b.NewContext(tele.Update{Callback: &tele.Callback{
+		ID:      "2",
+		Data:    "1",
+		Message: &tele.Message{ID: 1, Chat: &tele.Chat{ID: 1}},
+	}})
+	c.SetStdContext(ctx)
+	assert.True(t, errors.Is(p.onPage(c), context.Canceled))
+	assert.NotContains(t, requests, "editMessageReplyMarkup")
+
+	// the default page size
+	p.PageSize = 0
+	markup, err = p.Markup(nil, 0)
+	require.NoError(t, err)
+	assert.Len(t, markup.InlineKeyboard, 4)
+}
+
+func TestMenu(t *testing.T) {
+	b, requests := newTestBot(t)
+
+	english := tele.Btn{Text: "English", Unique: "lang", Data: "en"}
+	m := NewMenu(b, "menu", &MenuItem{
+		Text: "Settings",
+		Items: []*MenuItem{
+			{Text: "Language", Message: "Choose the language", Items: []*MenuItem{
+				{Btn: &english},
+				{Text: "More", Items: []*MenuItem{{Text: "Deep"}}},
+			}},
+			{Text: "About"},
+		},
+	})
+
+	text, markup := m.Render(nil)
+	assert.Equal(t, "Settings", text)
+	assert.Equal(t, [][]string{{"Language"}, {"About"}}, buttonTexts(markup))
+	assert.Equal(t, "0", markup.InlineKeyboard[0][0].Data)
+
+	text, markup = m.Render([]int{0})
+	assert.Equal(t, "Settings › Language\n\nChoose the language", text)
+	assert.Equal(t, [][]string{{"English"}, {"More"}, {"‹ Back"}}, buttonTexts(markup))
+	assert.Equal(t, "lang", markup.InlineKeyboard[0][0].Unique)
+	assert.Equal(t, "0.1", markup.InlineKeyboard[1][0].Data)
+	assert.Equal(t, "", markup.InlineKeyboard[2][0].Data)
+
+	text, markup = m.Render([]int{0, 1})
+	assert.Equal(t, "Settings › Language › More", text)
+	assert.Equal(t, [][]string{{"Deep"}, {"‹ Back", "« Home"}}, buttonTexts(markup))
+	assert.Equal(t, "0", markup.InlineKeyboard[1][0].Data)
+
+	// invalid path falls back to the valid part
+	text, _ = m.Render([]int{1, 5})
+	assert.Equal(t, "Settings › About", text)
+
+	b.ProcessUpdate(tele.Update{Callback: &tele.Callback{
+		ID:      "1",
+		Data:    "\fmenu|0",
+		Message: &tele.Message{ID: 1, Chat: &tele.Chat{ID: 1}},
+	}})
+	assert.Equal(t, "Settings › Language\n\nChoose the language", requests["editMessageText"]["text"])
+}
+
+func TestCalendar(t *testing.T) {
+	b, requests := newTestBot(t)
+
+	var picked time.Time
+	cal := NewCalendar(b, "cal", func(c tele.Context, t time.Time) error {
+		picked = t
+		return nil
+	})
+	cal.Min = time.Date(2024, 5, 10, 12, 20, 0, 0, time.UTC)
+	cal.Max = time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	cal.Time = true
+	cal.MinuteStep = 20
+
+	markup := cal.Markup(nil, cal.Min)
+	texts := buttonTexts(markup)
+	assert.Equal(t, []string{" ", "May 2024", "›"}, texts[0])
+	assert.Equal(t, []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"}, texts[1])
+	assert.Equal(t, []string{" ", " ", "·", "·", "·", "·", "·"}, texts[2])
+	assert.Equal(t, []string{"·", "·", "·", "10", "11", "12"}, texts[3][1:])
+	assert.Equal(t, "d2024-05-10", markup.InlineKeyboard[3][4].Data)
+	assert.Equal(t, "m2024-06", markup.InlineKeyboard[0][2].Data)
+
+	cal.SundayFirst = true
+	texts = buttonTexts(cal.Markup(nil, cal.Max))
+	assert.Equal(t, []string{"‹", "June 2024", " "}, texts[0])
+	assert.Equal(t, []string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}, texts[1])
+	assert.Equal(t, []string{" ", " ", " ", " ", " ", " ", "1"}, texts[2])
+	assert.Equal(t, []string{"·", "·", "·", "·", "·", "·", "·"}, texts[5])
+
+	callback := func(data string) {
+		b.ProcessUpdate(tele.Update{Callback: &tele.Callback{
+			ID:      "1",
+			Data:    "\fcal|" + data,
+			Message: &tele.Message{ID: 1, Chat: &tele.Chat{ID: 1}},
+		}})
+	}
+
+	edited := func() [][]string {
+		var markup tele.ReplyMarkup
+		require.NoError(t, json.Unmarshal([]byte(requests["editMessageReplyMarkup"]["reply_markup"]), &markup))
+		return buttonTexts(&markup)
+	}
+
+	callback("d2024-05-10")
+	texts = edited()
+	assert.Equal(t, []string{"10 May 2024"}, texts[0])
+	assert.Equal(t, []string{"·", "·", "·", "·", "·", "·"}, texts[2])
+	assert.Equal(t, []string{"12", "13", "14", "15", "16", "17"}, texts[3])
+	assert.Equal(t, []string{"‹"}, texts[5])
+
+	callback("h2024-05-10T12")
+	assert.Equal(t, [][]string{{"10 May 2024"}, {"·", "12:20", "12:40"}, {"‹"}}, edited())
+
+	// out of bounds
+	callback("t2024-05-10T12:00")
+	assert.True(t, picked.IsZero())
+
+	callback("t2024-05-10T12:40")
+	assert.Equal(t, time.Date(2024, 5, 10, 12, 40, 0, 0, time.UTC), picked)
+
+	cal.Time = false
+	callback("d2024-06-15")
+	assert.Equal(t, time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), picked)
+}
+
+func TestLayoutLocale(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "locales"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "locales", "uk.yml"), []byte(
+		"calendar:\n"+
+			"  months: Січень Лютий Березень Квітень Травень Червень Липень Серпень Вересень Жовтень Листопад Грудень\n"+
+			"  weekdays: Пн Вт Ср Чт Пт Сб Нд\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "locales", "en.yml"), []byte(
+		"calendar:\n  months: Jan Feb Mar Apr May Jun Jul Aug Sep Oct Nov Dec\n  weekdays: M T W T F S\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "locales", "de.yml"), []byte(
+		"hello: Hallo\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bot.yml"), []byte(
+		"settings:\n  locales_dir: "+filepath.Join(dir, "locales")+"\n"), 0600))
+
+	lt, err := layout.New(filepath.Join(dir, "bot.yml"))
+	require.NoError(t, err)
+
+	b, _ := newTestBot(t)
+	c := b.NewContext(tele.Update{})
+
+	locale := LayoutLocale(lt)
+	assert.Equal(t, CalendarEnglish, locale(c))
+
+	lt.SetLocale(c, "uk")
+	assert.Equal(t, "Травень", locale(c).Months[4])
+	assert.Equal(t, "Нд", locale(c).Weekdays[6])
+
+	// incomplete texts
+	lt.SetLocale(c, "en")
+	assert.Equal(t, "May", locale(c).Months[4])
+	assert.Equal(t, "Su", locale(c).Weekdays[6])
+
+	// no calendar texts at all
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	lt.SetLocale(c, "de")
+	assert.Equal(t, CalendarEnglish, locale(c))
+	assert.Empty(t, logs.String())
+}